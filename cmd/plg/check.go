@@ -3,59 +3,92 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/cli/cliutil"
-	"github.com/gbrlsnchs/pilgo/config"
 	"github.com/gbrlsnchs/pilgo/fs"
 	"github.com/gbrlsnchs/pilgo/linker"
 	"github.com/gbrlsnchs/pilgo/parser"
-	"gopkg.in/yaml.v3"
 )
 
+// Exit codes for "check -fail", from the best to the worst outcome. They skip 1 and 2,
+// which are used by the CLI library for other errors and for invalid usage.
+const (
+	// exitDone means all targets are already linked.
+	exitDone = 0
+	// exitReady means there are targets ready to be linked.
+	exitReady = iota + 2
+	// exitConflict means there are conflicts.
+	exitConflict
+	// exitMissing means there are targets that don't exist.
+	exitMissing
+	// exitError means the configuration is invalid or couldn't be read.
+	exitError
+)
+
+// errPending means there are targets ready to be linked.
+var errPending = errors.New("there are targets ready to be linked")
+
+// summaryStatuses are the statuses counted in the summary, in order.
+var summaryStatuses = []parser.Status{
+	parser.StatusDone,
+	parser.StatusReady,
+	parser.StatusConflict,
+	parser.StatusError,
+}
+
 type checkCmd struct {
-	fail bool
-	tags cliutil.CommaSepOptionSet
+	fail   bool
+	tags   cliutil.CommaSepOptionSet
+	format string
+	only   cliutil.CommaSepOptionSet
+	print  printFlags
 }
 
 func (cmd *checkCmd) register(getcfg func() appConfig) cli.ExecFunc {
-	return func(prg cli.Program) error {
-		appcfg := getcfg()
-		fs := fs.New(appcfg.fs)
-		b, err := fs.ReadFile(appcfg.conf)
-		if err != nil {
-			return err
+	return func(prg cli.Program) (err error) {
+		if cmd.fail {
+			defer func() {
+				var cerr *codeError
+				if err != nil && !errors.As(err, &cerr) {
+					err = &codeError{exitError, err}
+				}
+			}()
 		}
-		c := new(config.Config)
-		if yaml.Unmarshal(b, c); err != nil {
+		if err := checkFormat(cmd.format); err != nil {
 			return err
 		}
-		userConfigDir, err := appcfg.userConfigDir()
+		var mask parser.Status
+		for name := range cmd.only {
+			st, err := parser.ParseStatus(name)
+			if err != nil {
+				return err
+			}
+			mask |= st
+		}
+		appcfg := getcfg()
+		popts, err := cmd.print.options(appcfg)
 		if err != nil {
 			return err
 		}
-		homeConfigDir, err := appcfg.userHomeDir()
+		fs := fs.New(fs.ReadOnly(appcfg.fs))
+		c, err := loadConfig(prg, fs, appcfg.conf)
 		if err != nil {
 			return err
 		}
-		cwd, err := appcfg.getwd()
+		opts, err := parseOptions(appcfg)
 		if err != nil {
 			return err
 		}
 		var p parser.Parser
-		tr, err := p.Parse(c,
-			parser.BaseDirs(map[parser.Mode]string{
-				parser.UserMode: userConfigDir,
-				parser.HomeMode: homeConfigDir,
-			}),
-			parser.Cwd(cwd),
-			parser.Envsubst,
-			parser.Tags(cmd.tags))
+		tr, err := p.ParseContext(appcfg.ctx, c, append(opts, parser.Tags(cmd.tags))...)
 		if err != nil {
+			printCollisions(prg, err)
 			return err
 		}
-		ln := linker.New(fs)
-		if err = ln.Resolve(tr); err != nil {
+		ln := linker.New(fs, linkerOptions(prg, appcfg)...)
+		if err = ln.ResolveContext(appcfg.ctx, tr); err != nil {
 			var cft *linker.ConflictError
 			if errors.As(err, &cft) {
 				if !cmd.fail {
@@ -66,14 +99,58 @@ func (cmd *checkCmd) register(getcfg func() appConfig) cli.ExecFunc {
 				for _, err := range cft.Errs {
 					fmt.Fprintf(errw, "%s: %v\n", name, err)
 				}
+				return &codeError{exitCode(tr), err}
 			}
 			return err
 		}
 		if cmd.fail {
+			if code := exitCode(tr); code != exitDone {
+				return &codeError{code, errPending}
+			}
 			return nil
 		}
 	printtree:
-		fmt.Fprint(prg.Stdout(), tr)
+		printed := tr
+		if mask != 0 {
+			printed = tr.Filter(mask)
+		}
+		if err := printTree(prg, printed, cmd.format, popts...); err != nil {
+			return err
+		}
+		if cmd.format == "" || cmd.format == formatText {
+			if s := summary(tr); s != "" {
+				fmt.Fprintf(prg.Stdout(), "\n%s\n", s)
+			}
+		}
 		return nil
 	}
 }
+
+// exitCode returns the exit code for the worst status in tr.
+func exitCode(tr *parser.Tree) int {
+	code := exitDone
+	tr.Walk(func(n *parser.Node) error {
+		switch {
+		case n.Status == parser.StatusError:
+			code = exitMissing
+		case n.Status == parser.StatusConflict && code < exitConflict:
+			code = exitConflict
+		case n.Status == parser.StatusReady && code < exitReady:
+			code = exitReady
+		}
+		return nil
+	})
+	return code
+}
+
+// summary returns how many nodes in tr have each status, e.g. "2 done, 1 conflict".
+func summary(tr *parser.Tree) string {
+	count := tr.Count()
+	var counts []string
+	for _, st := range summaryStatuses {
+		if n := count[st]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, strings.ToLower(st.String())))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
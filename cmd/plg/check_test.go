@@ -24,6 +24,7 @@ func TestCheck(t *testing.T) {
 		cmd       checkCmd
 		want      fstest.InMemoryDriver
 		conflicts bool
+		code      int
 		err       error
 	}{
 		{
@@ -334,6 +335,7 @@ func TestCheck(t *testing.T) {
 				},
 			},
 			conflicts: true,
+			code:      exitMissing,
 			err:       nil,
 		},
 		{
@@ -854,6 +856,7 @@ func TestCheck(t *testing.T) {
 				},
 			},
 			conflicts: true,
+			code:      exitMissing,
 			err:       nil,
 		},
 		{
@@ -971,7 +974,8 @@ func TestCheck(t *testing.T) {
 				},
 			},
 			conflicts: true,
-			err:       nil,
+			code:      exitReady,
+			err:       errPending,
 		},
 		{
 			// NOTE(gbrlsnchs): Bug caught on my own dotfiles.
@@ -1198,6 +1202,459 @@ func TestCheck(t *testing.T) {
 			conflicts: false,
 			err:       nil,
 		},
+		{
+			name: "fail conflict",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"root": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"dir": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     nil,
+												Children: map[string]fstest.File{
+													"subdir": {
+														Linkname: "",
+														Perm:     os.ModePerm,
+														Data:     nil,
+														Children: map[string]fstest.File{
+															"target_1": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"foo": {Data: []byte("foo")},
+																	"bar": {Data: []byte("bar")},
+																},
+															},
+															"target_2": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"baz": {Data: []byte("baz")},
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									"fail_conflict.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"root"},
+											Options: map[string]*config.Config{
+												"root": {
+													BaseDir: fstest.AbsPath("etc"),
+													Targets: []string{"dir"},
+													Options: map[string]*config.Config{
+														"dir": {
+															Targets: []string{"subdir"},
+															Flatten: true,
+														},
+													},
+													Flatten: true,
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+					"etc": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"subdir": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"target_1": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"foo": {Data: []byte("foo")},
+											"bar": {Data: []byte("bar")},
+										},
+									},
+									"target_2": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"qux": {Data: []byte("qux")},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: checkCmd{fail: true},
+			want: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"root": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"dir": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     nil,
+												Children: map[string]fstest.File{
+													"subdir": {
+														Linkname: "",
+														Perm:     os.ModePerm,
+														Data:     nil,
+														Children: map[string]fstest.File{
+															"target_1": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"foo": {Data: []byte("foo")},
+																	"bar": {Data: []byte("bar")},
+																},
+															},
+															"target_2": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"baz": {Data: []byte("baz")},
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									"fail_conflict.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"root"},
+											Options: map[string]*config.Config{
+												"root": {
+													BaseDir: fstest.AbsPath("etc"),
+													Targets: []string{"dir"},
+													Options: map[string]*config.Config{
+														"dir": {
+															Targets: []string{"subdir"},
+															Flatten: true,
+														},
+													},
+													Flatten: true,
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+					"etc": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"subdir": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"target_1": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"foo": {Data: []byte("foo")},
+											"bar": {Data: []byte("bar")},
+										},
+									},
+									"target_2": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"qux": {Data: []byte("qux")},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			conflicts: true,
+			code:      exitConflict,
+			err:       nil,
+		},
+		{
+			name: "only",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"root": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"dir": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     nil,
+												Children: map[string]fstest.File{
+													"subdir": {
+														Linkname: "",
+														Perm:     os.ModePerm,
+														Data:     nil,
+														Children: map[string]fstest.File{
+															"target_1": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"foo": {Data: []byte("foo")},
+																	"bar": {Data: []byte("bar")},
+																},
+															},
+															"target_2": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"baz": {Data: []byte("baz")},
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									"only.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"root"},
+											Options: map[string]*config.Config{
+												"root": {
+													BaseDir: fstest.AbsPath("etc"),
+													Targets: []string{"dir"},
+													Options: map[string]*config.Config{
+														"dir": {
+															Targets: []string{"subdir"},
+															Flatten: true,
+														},
+													},
+													Flatten: true,
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+					"etc": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"subdir": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"target_1": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"foo": {Data: []byte("foo")},
+											"bar": {Data: []byte("bar")},
+										},
+									},
+									"target_2": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"qux": {Data: []byte("qux")},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: checkCmd{
+				only: cliutil.CommaSepOptionSet{
+					"conflict": struct{}{},
+				},
+			},
+			want: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"root": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"dir": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     nil,
+												Children: map[string]fstest.File{
+													"subdir": {
+														Linkname: "",
+														Perm:     os.ModePerm,
+														Data:     nil,
+														Children: map[string]fstest.File{
+															"target_1": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"foo": {Data: []byte("foo")},
+																	"bar": {Data: []byte("bar")},
+																},
+															},
+															"target_2": {
+																Linkname: "",
+																Perm:     os.ModePerm,
+																Data:     nil,
+																Children: map[string]fstest.File{
+																	"baz": {Data: []byte("baz")},
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									"only.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"root"},
+											Options: map[string]*config.Config{
+												"root": {
+													BaseDir: fstest.AbsPath("etc"),
+													Targets: []string{"dir"},
+													Options: map[string]*config.Config{
+														"dir": {
+															Targets: []string{"subdir"},
+															Flatten: true,
+														},
+													},
+													Flatten: true,
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+					"etc": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"subdir": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"target_1": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"foo": {Data: []byte("foo")},
+											"bar": {Data: []byte("bar")},
+										},
+									},
+									"target_2": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"qux": {Data: []byte("qux")},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			conflicts: false,
+			err:       nil,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1213,7 +1670,17 @@ func TestCheck(t *testing.T) {
 				prg  = clitest.NewProgram("check")
 				err  = exec(prg)
 			)
-			var rcv *linker.ConflictError
+			var (
+				rcv  *linker.ConflictError
+				cerr *codeError
+				code int
+			)
+			if errors.As(err, &cerr) {
+				code = cerr.code
+			}
+			if want, got := tc.code, code; got != want {
+				t.Fatalf("want exit code %d, got %d", want, got)
+			}
 			conflicts := errors.As(err, &rcv)
 			if !conflicts {
 				if want, got := tc.err, err; !errors.Is(got, want) {
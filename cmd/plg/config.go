@@ -1,18 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/cli/cliutil"
 	"github.com/gbrlsnchs/pilgo/config"
 	"github.com/gbrlsnchs/pilgo/fs"
-	"gopkg.in/yaml.v3"
 )
 
 type configCmd struct {
 	file    string
 	baseDir string
+	base    string
 	link    string
 	useHome boolptr
 	flatten bool
@@ -20,27 +21,30 @@ type configCmd struct {
 }
 
 func (cmd *configCmd) register(getcfg func() appConfig) func(cli.Program) error {
-	return func(_ cli.Program) error {
+	return func(prg cli.Program) error {
 		appcfg := getcfg()
 		fs := fs.New(appcfg.fs)
 		conf := appcfg.conf
-		b, err := fs.ReadFile(conf)
+		c, err := loadConfig(prg, fs, conf)
 		if err != nil {
 			return err
 		}
-		var c config.Config
-		if err := yaml.Unmarshal(b, &c); err != nil {
-			return err
+		if cmd.base != "" {
+			if _, ok := c.Roots[cmd.base]; !ok && !config.IsBuiltinBase(cmd.base) {
+				return fmt.Errorf("config: %w %q", config.ErrUnknownBase, cmd.base)
+			}
 		}
 		cc := &config.Config{
 			BaseDir: cmd.baseDir,
+			Base:    cmd.base,
 			Link:    cmd.link,
 			Flatten: cmd.flatten,
 			UseHome: cmd.useHome.addr,
 			Tags:    cmd.tags,
 		}
 		c.Set(cmd.file, cc, config.ModeConfig)
-		if b, err = marshalYAML(c); err != nil {
+		b, err := marshalYAML(c)
+		if err != nil {
 			return err
 		}
 		fi, err := fs.Stat(conf)
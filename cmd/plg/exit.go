@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gbrlsnchs/cli"
+)
+
+// exitInterrupt is the exit code for when the program is interrupted by SIGINT.
+const exitInterrupt = 130
+
+// codeError is an error that sets a specific exit code for the program.
+type codeError struct {
+	code int
+	err  error
+}
+
+func (e *codeError) Error() string { return e.err.Error() }
+
+func (e *codeError) Unwrap() error { return e.err }
+
+// withExitCode wraps exec in order to store the exit code of the error it returns, if any, in code.
+// Errors caused by an interrupt always set exitInterrupt.
+func withExitCode(exec cli.ExecFunc, code *int) cli.ExecFunc {
+	return func(prg cli.Program) error {
+		err := exec(prg)
+		var cerr *codeError
+		switch {
+		case errors.Is(err, context.Canceled):
+			*code = exitInterrupt
+		case errors.As(err, &cerr):
+			*code = cerr.code
+		}
+		return err
+	}
+}
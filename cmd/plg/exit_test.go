@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/cli/clitest"
+)
+
+func TestWithExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "no error",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "plain error",
+			err:  errors.New("oops"),
+			want: 0,
+		},
+		{
+			name: "code error",
+			err:  &codeError{code: exitConflict, err: errors.New("oops")},
+			want: exitConflict,
+		},
+		{
+			name: "canceled",
+			err:  fmt.Errorf("linker: %w", context.Canceled),
+			want: exitInterrupt,
+		},
+		{
+			name: "canceled code error",
+			err:  &codeError{code: exitError, err: context.Canceled},
+			want: exitInterrupt,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				code int
+				exec = withExitCode(func(cli.Program) error { return tc.err }, &code)
+				err  = exec(clitest.NewProgram("test"))
+			)
+			if want, got := tc.err, err; got != want {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, code; got != want {
+				t.Fatalf("want exit code %d, got %d", want, got)
+			}
+		})
+	}
+}
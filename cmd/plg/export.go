@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/cli/cliutil"
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/parser"
+)
+
+// Export formats.
+const formatSh = "sh"
+
+// shPrelude declares a function that mirrors how the linker resolves a node,
+// but at the time the script runs, so it doesn't depend on the state of the
+// machine where it was exported.
+const shPrelude = `set -eu
+
+dotfiles=${PILGO_DOTFILES:-%s}
+conflicts=0
+
+# resolve MODE TARGET LINK checks whether LINK can point to TARGET and,
+# in link mode, creates it. If a directory exists in place of LINK and
+# TARGET is also a directory, the target's files are linked inside it.
+resolve() {
+	if [ ! -e "$2" ]; then
+		conflict "$2: target doesn't exist"
+		return
+	fi
+	if [ -L "$3" ]; then
+		if [ "$(readlink "$3")" != "$2" ]; then
+			conflict "$3: file exists in place of link"
+		fi
+		return
+	fi
+	if [ ! -e "$3" ]; then
+		if [ "$1" = link ]; then
+			mkdir -p "$(dirname "$3")"
+			ln -s "$2" "$3"
+		fi
+		return
+	fi
+	if [ ! -d "$2" ]; then
+		conflict "$2: target can't be expanded"
+		return
+	fi
+	if [ ! -d "$3" ]; then
+		conflict "$3: file exists in place of link and is not expandable"
+		return
+	fi
+	for f in "$2"/* "$2"/.[!.]* "$2"/..?*; do
+		if [ -e "$f" ] || [ -L "$f" ]; then
+			resolve "$1" "$f" "$3/${f##*/}"
+		fi
+	done
+}
+
+conflict() {
+	echo "linker: $1" >&2
+	conflicts=$((conflicts + 1))
+}
+
+`
+
+// shEpilogue checks every link before creating any of them, just like the linker does.
+const shEpilogue = `
+resolve_all check
+if [ "$conflicts" -eq 1 ]; then
+	echo "linker: there is 1 conflict" >&2
+	exit 1
+fi
+if [ "$conflicts" -gt 1 ]; then
+	echo "linker: there are $conflicts conflicts" >&2
+	exit 1
+fi
+resolve_all link
+`
+
+type exportCmd struct {
+	format string
+	tags   cliutil.CommaSepOptionSet
+}
+
+func (cmd *exportCmd) register(getcfg func() appConfig) func(cli.Program) error {
+	return func(prg cli.Program) error {
+		switch cmd.format {
+		case "", formatSh:
+		default:
+			return fmt.Errorf("unknown format %q", cmd.format)
+		}
+		appcfg := getcfg()
+		fs := fs.New(fs.ReadOnly(appcfg.fs))
+		c, err := loadConfig(prg, fs, appcfg.conf)
+		if err != nil {
+			return err
+		}
+		opts, err := parseOptions(appcfg)
+		if err != nil {
+			return err
+		}
+		var p parser.Parser
+		tr, err := p.ParseContext(appcfg.ctx, c, append(opts, parser.Tags(cmd.tags))...)
+		if err != nil {
+			printCollisions(prg, err)
+			return err
+		}
+		cwd, err := appcfg.getwd()
+		if err != nil {
+			return err
+		}
+		home, err := appcfg.userHomeDir()
+		if err != nil {
+			return err
+		}
+		return writeSh(prg.Stdout(), tr, cwd, home)
+	}
+}
+
+// writeSh writes a POSIX shell script that links every target in tr. Paths
+// inside cwd are relative to the PILGO_DOTFILES environment variable, which
+// defaults to cwd, and paths inside home are relative to HOME. All paths use
+// forward slashes, even on Windows.
+func writeSh(w io.Writer, tr *parser.Tree, cwd, home string) error {
+	cwd, home = filepath.ToSlash(cwd), filepath.ToSlash(home)
+	var (
+		bw   = bufio.NewWriter(w)
+		dirs = []shDir{{"dotfiles", cwd}, {"HOME", home}}
+	)
+	fmt.Fprintln(bw, "#!/bin/sh")
+	fmt.Fprintln(bw, "# Generated by \"plg export\". It is safe to run it more than once.")
+	fmt.Fprintf(bw, shPrelude, shQuote(cwd))
+	fmt.Fprintln(bw, "resolve_all() {")
+	tr.Walk(func(n *parser.Node) error {
+		if len(n.Children) > 0 || len(n.Link.Path) == 0 {
+			return nil
+		}
+		fmt.Fprintf(bw, "\tresolve \"$1\" %s %s\n",
+			shPath(filepath.ToSlash(n.Target.FullPath()), dirs),
+			shPath(filepath.ToSlash(n.Link.FullPath()), dirs))
+		return nil
+	})
+	// Functions can't have an empty body.
+	fmt.Fprintln(bw, "\t:")
+	fmt.Fprintln(bw, "}")
+	fmt.Fprint(bw, shEpilogue)
+	return bw.Flush()
+}
+
+// shDir is a directory held by a shell variable.
+type shDir struct {
+	name string
+	dir  string
+}
+
+// shPath quotes path, replacing the first directory in dirs
+// that contains it with a reference to its variable.
+func shPath(path string, dirs []shDir) string {
+	for _, d := range dirs {
+		if path == d.dir {
+			return fmt.Sprintf("\"$%s\"", d.name)
+		}
+		if rel := strings.TrimPrefix(path, d.dir+"/"); rel != path {
+			return fmt.Sprintf("\"$%s\"%s", d.name, shQuote("/"+rel))
+		}
+	}
+	return shQuote(path)
+}
+
+// shQuote quotes s for the shell using single quotes.
+func shQuote(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" }
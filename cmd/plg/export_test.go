@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andybalholm/crlf"
+	"github.com/gbrlsnchs/cli/clitest"
+	"github.com/gbrlsnchs/cli/cliutil"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/gbrlsnchs/pilgo/internal"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/transform"
+)
+
+func TestExport(t *testing.T) {
+	testCases := []struct {
+		name string
+		drv  fstest.InMemoryDriver
+		cmd  exportCmd
+		err  error
+	}{
+		{
+			name: "default",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"default.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{
+												"foo",
+												"bar",
+												"it's",
+											},
+											Options: map[string]*config.Config{
+												"bar": {
+													Flatten: true,
+													UseHome: internal.NewBool(true),
+													Targets: []string{"baz"},
+												},
+												"it's": {
+													BaseDir: "/etc",
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: exportCmd{},
+			err: nil,
+		},
+		{
+			name: "tags",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"tags.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{
+												"foo",
+												"test",
+											},
+											Options: map[string]*config.Config{
+												"test": {
+													Tags: []string{"test"},
+												},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: exportCmd{
+				format: formatSh,
+				tags: cliutil.CommaSepOptionSet{
+					"test": struct{}{},
+				},
+			},
+			err: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				appcfg = appConfig{
+					conf:          filepath.Base(t.Name()) + ".yml",
+					fs:            &tc.drv,
+					getwd:         func() (string, error) { return fstest.AbsPath("home", "dotfiles"), nil },
+					userConfigDir: func() (string, error) { return fstest.AbsPath("home", "config"), nil },
+					userHomeDir:   func() (string, error) { return fstest.AbsPath("home"), nil },
+				}
+				exec = tc.cmd.register(appcfg.copy)
+				prg  = clitest.NewProgram("export")
+				err  = exec(prg)
+			)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			golden := filepath.Join("testdata", t.Name()) + ".golden"
+			// Scripts always use forward slashes,
+			// so the golden file is not converted.
+			b, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _, err = transform.Bytes(new(crlf.Normalize), b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := string(b), prg.Output(); got != want {
+				t.Errorf("\"export\" command stdout mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			if want, got := "", prg.ErrOutput(); got != want {
+				t.Errorf("\"export\" command stderr mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+		})
+	}
+	t.Run("unknown format", func(t *testing.T) {
+		var (
+			cmd  = exportCmd{format: "bat"}
+			exec = cmd.register(func() appConfig { return appConfig{} })
+			err  = exec(clitest.NewProgram("export"))
+		)
+		if want, got := `unknown format "bat"`, err; got == nil || got.Error() != want {
+			t.Fatalf("want %q, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,585 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/internal"
+	"github.com/gbrlsnchs/pilgo/linker"
+)
+
+const (
+	// stowDotPrefix is the prefix GNU Stow replaces with a dot when using its "--dotfiles" option.
+	stowDotPrefix = "dot-"
+	// maxLinkCost is the cost of links that can't be reproduced.
+	maxLinkCost = 1 << 30
+)
+
+var (
+	errNotPackage     = errors.New("package must be a directory in the current directory")
+	errEmptyPackage   = errors.New("package is empty")
+	errNoLinks        = errors.New("no links to the current directory were found")
+	errRelativeLink   = errors.New("relative links are not supported")
+	errTargetImported = errors.New("target is already imported from another link")
+)
+
+// stowIgnored are files GNU Stow ignores by default in any directory.
+var stowIgnored = map[string]struct{}{
+	".git":               {},
+	".gitignore":         {},
+	".gitmodules":        {},
+	".hg":                {},
+	".svn":               {},
+	".cvsignore":         {},
+	".stow-local-ignore": {},
+	"CVS":                {},
+	"RCS":                {},
+	"_darcs":             {},
+}
+
+// stowTopIgnored are prefixes of files GNU Stow ignores by default
+// when they're directly inside a package.
+var stowTopIgnored = []string{"README", "LICENSE", "COPYING"}
+
+type importCmd struct {
+	links importLinksCmd
+	stow  importStowCmd
+}
+
+type importStowCmd struct {
+	force    bool
+	packages []string
+}
+
+func (cmd *importStowCmd) register(getcfg func() appConfig) func(cli.Program) error {
+	return func(_ cli.Program) error {
+		var (
+			appcfg = getcfg()
+			fs     = fs.New(appcfg.fs)
+		)
+		cwd, err := appcfg.getwd()
+		if err != nil {
+			return err
+		}
+		pkgs := make([]*stowEntry, 0, len(cmd.packages))
+		for _, name := range cmd.packages {
+			name = filepath.Clean(name)
+			if strings.ContainsRune(name, filepath.Separator) || name == "." || name == ".." {
+				return fmt.Errorf("import: %s: %w", name, errNotPackage)
+			}
+			fi, err := fs.Stat(name)
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() {
+				return fmt.Errorf("import: %s: %w", name, errNotPackage)
+			}
+			pkg, err := readStowEntry(appcfg.ctx, fs, cwd, name, 0)
+			if err != nil {
+				return err
+			}
+			if len(pkg.children) == 0 {
+				return fmt.Errorf("import: %s: %w", name, errEmptyPackage)
+			}
+			pkgs = append(pkgs, pkg)
+		}
+		return createConfig(fs, appcfg.conf, stowConfig(pkgs), cmd.force)
+	}
+}
+
+// stowEntry is a file inside a GNU Stow package.
+type stowEntry struct {
+	name     string
+	link     string
+	children []*stowEntry
+}
+
+func readStowEntry(ctx context.Context, fs fs.FileSystem, dir, name string, depth int) (*stowEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e := &stowEntry{name: name, link: name}
+	if depth > 0 && strings.HasPrefix(name, stowDotPrefix) && len(name) > len(stowDotPrefix) {
+		e.link = "." + strings.TrimPrefix(name, stowDotPrefix)
+	}
+	path := filepath.Join(dir, name)
+	fi, err := fs.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() || fi.Linkname() != "" {
+		return e, nil
+	}
+	files, err := fs.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		if stowIgnore(fi.Name(), depth+1) {
+			continue
+		}
+		c, err := readStowEntry(ctx, fs, path, fi.Name(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		e.children = append(e.children, c)
+	}
+	return e, nil
+}
+
+func stowIgnore(name string, depth int) bool {
+	if _, ok := stowIgnored[name]; ok {
+		return true
+	}
+	if strings.HasSuffix(name, "~") || strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#") {
+		return true
+	}
+	if depth == 1 {
+		for _, prefix := range stowTopIgnored {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stowConfig returns a configuration that links pkgs like GNU Stow does. Every package
+// is flattened and uses the home directory as its base directory. Files are linked as
+// high in the tree as possible, except for directories that more than one package has,
+// whose files are linked individually.
+func stowConfig(pkgs []*stowEntry) *config.Config {
+	shared := make(map[string]int)
+	for _, pkg := range pkgs {
+		countStowLinks(pkg.children, "", shared)
+	}
+	c := &config.Config{
+		Targets: make([]string, 0, len(pkgs)),
+		Options: make(map[string]*config.Config, len(pkgs)),
+	}
+	for _, pkg := range pkgs {
+		cc := &config.Config{
+			Flatten: true,
+			UseHome: internal.NewBool(true),
+		}
+		setStowTargets(cc, pkg.children, "", shared)
+		c.Targets = append(c.Targets, pkg.name)
+		c.Options[pkg.name] = cc
+	}
+	return c
+}
+
+func countStowLinks(entries []*stowEntry, dir string, count map[string]int) {
+	for _, e := range entries {
+		link := filepath.Join(dir, e.link)
+		count[link]++
+		countStowLinks(e.children, link, count)
+	}
+}
+
+func setStowTargets(c *config.Config, entries []*stowEntry, dir string, shared map[string]int) {
+	for _, e := range entries {
+		var (
+			link = filepath.Join(dir, e.link)
+			cc   config.Config
+		)
+		c.Targets = append(c.Targets, e.name)
+		if e.link != e.name {
+			cc.Link = e.link
+		}
+		if len(e.children) > 0 && shared[link] > 1 {
+			setStowTargets(&cc, e.children, link, shared)
+		}
+		if cc.Link == "" && len(cc.Targets) == 0 {
+			continue
+		}
+		if c.Options == nil {
+			c.Options = make(map[string]*config.Config)
+		}
+		c.Options[e.name] = &cc
+	}
+}
+
+type importLinksCmd struct {
+	force bool
+}
+
+func (cmd *importLinksCmd) register(getcfg func() appConfig) func(cli.Program) error {
+	return func(prg cli.Program) error {
+		var (
+			appcfg = getcfg()
+			fs     = fs.New(appcfg.fs)
+		)
+		cwd, err := appcfg.getwd()
+		if err != nil {
+			return err
+		}
+		userConfigDir, err := appcfg.userConfigDir()
+		if err != nil {
+			return err
+		}
+		home, err := appcfg.userHomeDir()
+		if err != nil {
+			return err
+		}
+		var (
+			root = &linkNode{}
+			exe  = prg.Name()
+			errw = prg.Stderr()
+			// The configuration directory is usually inside the home directory.
+			skip = map[string]struct{}{cwd: {}, userConfigDir: {}}
+		)
+		for _, b := range []struct{ base, dir string }{
+			{config.BaseConfig, userConfigDir},
+			{config.BaseHome, home},
+		} {
+			b := b
+			fi, err := fs.Stat(b.dir)
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() {
+				continue
+			}
+			err = walkLinks(appcfg.ctx, fs, b.dir, skip, func(path, linkname string) error {
+				rel, err := filepath.Rel(b.dir, path)
+				if err != nil {
+					return err
+				}
+				ln := &importedLink{
+					base: b.base,
+					dir:  b.dir,
+					path: strings.Split(rel, string(filepath.Separator)),
+				}
+				switch err := root.insert(fs, cwd, ln, linkname); {
+				case errors.Is(err, errRelativeLink),
+					errors.Is(err, errTargetImported),
+					errors.Is(err, linker.ErrTargetNotExist):
+					fmt.Fprintf(errw, "%s: import: %s: %v\n", exe, path, err)
+					return nil
+				default:
+					return err
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(root.children) == 0 {
+			return fmt.Errorf("import: %w", errNoLinks)
+		}
+		return createConfig(fs, appcfg.conf, root.config(), cmd.force)
+	}
+}
+
+// walkLinks calls fn for every symlink inside dir, recursively. It doesn't follow
+// symlinks and doesn't descend into directories in skip or that it can't read.
+// It stops as soon as ctx is done.
+func walkLinks(ctx context.Context, fs fs.FileSystem, dir string, skip map[string]struct{}, fn func(path, linkname string) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	files, err := fs.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil
+		}
+		return err
+	}
+	for _, fi := range files {
+		path := filepath.Join(dir, fi.Name())
+		if _, ok := skip[path]; ok {
+			continue
+		}
+		if linkname := fi.Linkname(); linkname != "" {
+			if err := fn(path, linkname); err != nil {
+				return err
+			}
+			continue
+		}
+		if fi.IsDir() {
+			if err := walkLinks(ctx, fs, path, skip, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importedLink is a symlink found inside a base directory.
+type importedLink struct {
+	base string
+	dir  string
+	path []string
+}
+
+// linkNode is a target of imported links. Only leaves have links.
+type linkNode struct {
+	name     string
+	link     *importedLink
+	children map[string]*linkNode
+	plans    map[string]linkPlan // by prefix
+}
+
+// linkPlan is a configuration planned for a linkNode and its cost.
+type linkPlan struct {
+	c    *config.Config
+	cost int
+}
+
+// insert adds ln as the link of the target linkname points to, if it's inside cwd.
+// Since targets can only have one link, a target that already has a link or that
+// is inside or contains another linked target is not imported.
+func (n *linkNode) insert(fs fs.FileSystem, cwd string, ln *importedLink, linkname string) error {
+	rel := strings.TrimPrefix(linkname, cwd+string(filepath.Separator))
+	if rel == linkname || filepath.Clean(linkname) != linkname {
+		abs := filepath.Join(append([]string{ln.dir}, ln.path...)...)
+		abs = filepath.Join(filepath.Dir(abs), linkname)
+		if !filepath.IsAbs(linkname) && strings.HasPrefix(abs, cwd+string(filepath.Separator)) {
+			return errRelativeLink
+		}
+		return nil
+	}
+	fi, err := fs.Stat(linkname)
+	if err != nil {
+		return err
+	}
+	if !fi.Exists() {
+		return linker.ErrTargetNotExist
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if n.link != nil {
+			return errTargetImported
+		}
+		c, ok := n.children[name]
+		if !ok {
+			c = &linkNode{name: name}
+			if n.children == nil {
+				n.children = make(map[string]*linkNode)
+			}
+			n.children[name] = c
+		}
+		n = c
+	}
+	if n.link != nil || len(n.children) > 0 {
+		return errTargetImported
+	}
+	n.link = ln
+	return nil
+}
+
+func (n *linkNode) names() []string {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// config returns a configuration whose targets are linked exactly like the imported links.
+func (n *linkNode) config() *config.Config {
+	c := new(config.Config)
+	for _, name := range n.names() {
+		cc, _ := n.children[name].plan(nil)
+		c.Targets = append(c.Targets, name)
+		if c.Options == nil {
+			c.Options = make(map[string]*config.Config, len(n.children))
+		}
+		c.Options[name] = cc
+	}
+	n.setBase(c, config.BaseConfig)
+	pruneOptions(c)
+	return c
+}
+
+// plan returns the cheapest configuration that reproduces the links below n when its
+// parents are linked to prefix, and its cost. Options cost one each, except for baseDir,
+// which costs two, so it's only used when links can't be reproduced otherwise.
+// Plans are memoized, since every choice of a parent plans its children again.
+func (n *linkNode) plan(prefix []string) (*config.Config, int) {
+	key := strings.Join(prefix, string(filepath.Separator))
+	if p, ok := n.plans[key]; ok {
+		return p.c, p.cost
+	}
+	c, cost := n.newPlan(prefix)
+	if n.plans == nil {
+		n.plans = make(map[string]linkPlan)
+	}
+	n.plans[key] = linkPlan{c, cost}
+	return c, cost
+}
+
+func (n *linkNode) newPlan(prefix []string) (*config.Config, int) {
+	if ln := n.link; ln != nil {
+		return ln.plan(n.name, prefix)
+	}
+	// Try using the target's name first, then flattening it
+	// and then renaming it to what links below it have in common.
+	renames := make(map[string]struct{})
+	n.nextLinks(prefix, renames)
+	delete(renames, n.name)
+	choices := []string{n.name, ""}
+	for link := range renames {
+		choices = append(choices, link)
+	}
+	sort.Strings(choices[2:])
+	var (
+		best     *config.Config
+		bestCost = maxLinkCost
+	)
+	for _, link := range choices {
+		var (
+			c    = new(config.Config)
+			cost int
+			p    = prefix
+		)
+		switch link {
+		case "":
+			c.Flatten = true
+			cost++
+		case n.name:
+			p = append(prefix[:len(prefix):len(prefix)], link)
+		default:
+			c.Link = link
+			cost++
+			p = append(prefix[:len(prefix):len(prefix)], link)
+		}
+		c.Options = make(map[string]*config.Config, len(n.children))
+		for _, name := range n.names() {
+			cc, ccost := n.children[name].plan(p)
+			c.Targets = append(c.Targets, name)
+			c.Options[name] = cc
+			if cost += ccost; cost >= maxLinkCost {
+				break
+			}
+		}
+		if cost < bestCost {
+			best, bestCost = c, cost
+		}
+	}
+	return best, bestCost
+}
+
+// nextLinks adds to links the names that come right after prefix in links below n.
+func (n *linkNode) nextLinks(prefix []string, links map[string]struct{}) {
+	if ln := n.link; ln != nil {
+		if len(ln.path) > len(prefix) && hasPathPrefix(ln.path, prefix) {
+			links[ln.path[len(prefix)]] = struct{}{}
+		}
+		return
+	}
+	for _, c := range n.children {
+		c.nextLinks(prefix, links)
+	}
+}
+
+// plan returns the configuration that reproduces ln for a target called
+// name whose parents are linked to prefix, and its cost.
+func (ln *importedLink) plan(name string, prefix []string) (*config.Config, int) {
+	var (
+		c    = new(config.Config)
+		cost int
+	)
+	if hasPathPrefix(ln.path, prefix) {
+		switch len(ln.path) - len(prefix) {
+		case 0:
+			c.Flatten = true
+			return c, 1
+		case 1:
+			if link := ln.path[len(ln.path)-1]; link != name {
+				c.Link = link
+				cost++
+			}
+			return c, cost
+		}
+	}
+	// Use the link's directory as base directory, as long as it ends with prefix.
+	var (
+		path = filepath.Join(append([]string{ln.dir}, ln.path...)...)
+		dir  = filepath.Dir(path)
+	)
+	if len(prefix) > 0 {
+		suffix := string(filepath.Separator) + filepath.Join(prefix...)
+		if !strings.HasSuffix(dir, suffix) {
+			return c, maxLinkCost
+		}
+		dir = strings.TrimSuffix(dir, suffix)
+	}
+	c.BaseDir = dir
+	cost += 2
+	if link := filepath.Base(path); link != name {
+		c.Link = link
+		cost++
+	}
+	return c, cost
+}
+
+// base returns the base shared by links below n, ignoring links that use a
+// base directory in c. If links below n have different bases, mixed is true.
+func (n *linkNode) base(c *config.Config) (base string, mixed bool) {
+	if n.link != nil {
+		if c.BaseDir != "" {
+			return "", false
+		}
+		return n.link.base, false
+	}
+	for name, cn := range n.children {
+		b, mixed := cn.base(c.Options[name])
+		if mixed || base != "" && b != "" && b != base {
+			return "", true
+		}
+		if b != "" {
+			base = b
+		}
+	}
+	return base, false
+}
+
+// setBase sets useHome as high as possible in c, where
+// every link below n has the same base directory.
+func (n *linkNode) setBase(c *config.Config, inherited string) {
+	base, mixed := n.base(c)
+	if !mixed {
+		if base != "" && base != inherited {
+			c.UseHome = internal.NewBool(base == config.BaseHome)
+		}
+		return
+	}
+	for name, cn := range n.children {
+		cn.setBase(c.Options[name], inherited)
+	}
+}
+
+// pruneOptions removes options that don't set anything from c.
+func pruneOptions(c *config.Config) {
+	for name, cc := range c.Options {
+		pruneOptions(cc)
+		if cc.BaseDir == "" && cc.Link == "" && len(cc.Targets) == 0 && !cc.Flatten && cc.UseHome == nil {
+			delete(c.Options, name)
+		}
+	}
+	if len(c.Options) == 0 {
+		c.Options = nil
+	}
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,788 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/cli/clitest"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/gbrlsnchs/pilgo/internal"
+	"github.com/gbrlsnchs/pilgo/linker"
+	"github.com/gbrlsnchs/pilgo/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImportStow(t *testing.T) {
+	stowPackages := map[string]fstest.File{
+		"git": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				".git": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"HEAD": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     []byte("HEAD"),
+							Children: nil,
+						},
+					},
+				},
+				"README.md": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("README.md"),
+					Children: nil,
+				},
+				"dot-config": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"git": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: map[string]fstest.File{
+								"config": {
+									Linkname: "",
+									Perm:     os.ModePerm,
+									Data:     []byte("config"),
+									Children: nil,
+								},
+							},
+						},
+					},
+				},
+				"dot-gitconfig": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("dot-gitconfig"),
+					Children: nil,
+				},
+			},
+		},
+		"nvim": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"dot-config": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"nvim": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: map[string]fstest.File{
+								"init.vim": {
+									Linkname: "",
+									Perm:     os.ModePerm,
+									Data:     []byte("init.vim"),
+									Children: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"zsh": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"dot-zshrc": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("dot-zshrc"),
+					Children: nil,
+				},
+				"dot-": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("dot-"),
+					Children: nil,
+				},
+				"zshrc~": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("zshrc~"),
+					Children: nil,
+				},
+			},
+		},
+		"empty": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"LICENSE": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("LICENSE"),
+					Children: nil,
+				},
+			},
+		},
+		"file": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     []byte("file"),
+			Children: nil,
+		},
+		"TestImportStow": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"config_exists.yml": {
+					Linkname: "",
+					Perm:     0o600,
+					Data:     []byte("targets: []\n"),
+					Children: nil,
+				},
+				"force.yml": {
+					Linkname: "",
+					Perm:     0o600,
+					Data:     []byte("targets: []\n"),
+					Children: nil,
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		name string
+		cmd  importStowCmd
+		want []byte
+		perm os.FileMode
+		err  error
+	}{
+		{
+			name: "default",
+			cmd:  importStowCmd{packages: []string{"git", "nvim", "zsh"}},
+			want: yamlData(config.Config{
+				Targets: []string{"git", "nvim", "zsh"},
+				Options: map[string]*config.Config{
+					"git": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"dot-config", "dot-gitconfig"},
+						Options: map[string]*config.Config{
+							"dot-config": {
+								Link:    ".config",
+								Targets: []string{"git"},
+							},
+							"dot-gitconfig": {
+								Link: ".gitconfig",
+							},
+						},
+					},
+					"nvim": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"dot-config"},
+						Options: map[string]*config.Config{
+							"dot-config": {
+								Link:    ".config",
+								Targets: []string{"nvim"},
+							},
+						},
+					},
+					"zsh": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"dot-", "dot-zshrc"},
+						Options: map[string]*config.Config{
+							"dot-zshrc": {
+								Link: ".zshrc",
+							},
+						},
+					},
+				},
+			}),
+			perm: 0o644,
+			err:  nil,
+		},
+		{
+			name: "single package",
+			cmd:  importStowCmd{packages: []string{"nvim/"}},
+			want: yamlData(config.Config{
+				Targets: []string{"nvim"},
+				Options: map[string]*config.Config{
+					"nvim": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"dot-config"},
+						Options: map[string]*config.Config{
+							"dot-config": {
+								Link: ".config",
+							},
+						},
+					},
+				},
+			}),
+			perm: 0o644,
+			err:  nil,
+		},
+		{
+			name: "force",
+			cmd:  importStowCmd{force: true, packages: []string{"zsh"}},
+			want: yamlData(config.Config{
+				Targets: []string{"zsh"},
+				Options: map[string]*config.Config{
+					"zsh": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"dot-", "dot-zshrc"},
+						Options: map[string]*config.Config{
+							"dot-zshrc": {
+								Link: ".zshrc",
+							},
+						},
+					},
+				},
+			}),
+			perm: 0o600,
+			err:  nil,
+		},
+		{
+			name: "config exists",
+			cmd:  importStowCmd{packages: []string{"zsh"}},
+			want: []byte("targets: []\n"),
+			perm: 0o600,
+			err:  errConfigExists,
+		},
+		{
+			name: "empty package",
+			cmd:  importStowCmd{packages: []string{"zsh", "empty"}},
+			want: nil,
+			err:  errEmptyPackage,
+		},
+		{
+			name: "not a directory",
+			cmd:  importStowCmd{packages: []string{"file"}},
+			want: nil,
+			err:  errNotPackage,
+		},
+		{
+			name: "not in current directory",
+			cmd:  importStowCmd{packages: []string{"git/dot-config"}},
+			want: nil,
+			err:  errNotPackage,
+		},
+		{
+			name: "current directory",
+			cmd:  importStowCmd{packages: []string{"."}},
+			want: nil,
+			err:  errNotPackage,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				drv = fstest.InMemoryDriver{
+					CurrentDir: "home/dotfiles",
+					Files: map[string]fstest.File{
+						"home": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: map[string]fstest.File{
+								"dotfiles": {
+									Linkname: "",
+									Perm:     os.ModePerm,
+									Data:     nil,
+									Children: copyFiles(stowPackages),
+								},
+							},
+						},
+					},
+				}
+				conf   = filepath.Join("TestImportStow", filepath.Base(t.Name())+".yml")
+				appcfg = appConfig{
+					conf:  conf,
+					fs:    &drv,
+					getwd: func() (string, error) { return fstest.AbsPath("home", "dotfiles"), nil },
+				}
+				exec = tc.cmd.register(appcfg.copy)
+				prg  = clitest.NewProgram("stow")
+				err  = exec(prg)
+			)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := "", prg.Output(); got != want {
+				t.Fatalf("\"import stow\" command output mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			fi, err := drv.Stat(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tc.want != nil, fi.Exists(); got != want {
+				t.Fatalf("want %t, got %t", want, got)
+			}
+			if tc.want == nil {
+				return
+			}
+			if want, got := tc.perm, fi.Perm(); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+			data, err := drv.ReadFile(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := string(tc.want), string(data); got != want {
+				t.Errorf("\"import stow\" command created a wrong configuration file (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func copyFiles(files map[string]fstest.File) map[string]fstest.File {
+	if files == nil {
+		return nil
+	}
+	cp := make(map[string]fstest.File, len(files))
+	for name, f := range files {
+		f.Children = copyFiles(f.Children)
+		cp[name] = f
+	}
+	return cp
+}
+
+func TestImportLinks(t *testing.T) {
+	dotfiles := map[string]fstest.File{
+		".vimrc": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     []byte(".vimrc"),
+			Children: nil,
+		},
+		"git": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"config": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("config"),
+					Children: nil,
+				},
+				"ignore": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("ignore"),
+					Children: nil,
+				},
+			},
+		},
+		"nvim": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"init.vim": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("init.vim"),
+					Children: nil,
+				},
+			},
+		},
+		"zsh": {
+			Linkname: "",
+			Perm:     os.ModePerm,
+			Data:     nil,
+			Children: map[string]fstest.File{
+				"zprofile": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("zprofile"),
+					Children: nil,
+				},
+				"zshrc": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     []byte("zshrc"),
+					Children: nil,
+				},
+			},
+		},
+		"TestImportLinks.yml": {
+			Linkname: "",
+			Perm:     0o600,
+			Data:     []byte("targets: []\n"),
+			Children: nil,
+		},
+	}
+	testCases := []struct {
+		name string
+		home map[string]fstest.File
+		cmd  importLinksCmd
+		want []byte
+		perm os.FileMode
+		out  string
+		err  error
+	}{
+		{
+			name: "default",
+			home: map[string]fstest.File{
+				".vimrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", ".vimrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				".zprofile": {
+					Linkname: fstest.AbsPath("home", "dotfiles", "zsh", "zprofile"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				".zshrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", "zsh", "zshrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				"config": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"git": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: map[string]fstest.File{
+								"config": {
+									Linkname: fstest.AbsPath("home", "dotfiles", "git", "config"),
+									Perm:     os.ModePerm,
+									Data:     nil,
+									Children: nil,
+								},
+							},
+						},
+						"nvim": {
+							Linkname: fstest.AbsPath("home", "dotfiles", "nvim"),
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: nil,
+						},
+					},
+				},
+				"hosts": {
+					Linkname: fstest.AbsPath("etc", "hosts"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+			},
+			cmd: importLinksCmd{force: true},
+			want: yamlData(config.Config{
+				Targets: []string{".vimrc", "git", "nvim", "zsh"},
+				Options: map[string]*config.Config{
+					".vimrc": {
+						UseHome: internal.NewBool(true),
+					},
+					"git": {
+						Targets: []string{"config"},
+					},
+					"zsh": {
+						Flatten: true,
+						UseHome: internal.NewBool(true),
+						Targets: []string{"zprofile", "zshrc"},
+						Options: map[string]*config.Config{
+							"zprofile": {
+								Link: ".zprofile",
+							},
+							"zshrc": {
+								Link: ".zshrc",
+							},
+						},
+					},
+				},
+			}),
+			perm: 0o600,
+			out:  "",
+			err:  nil,
+		},
+		{
+			name: "home",
+			home: map[string]fstest.File{
+				".vimrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", ".vimrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				".zshrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", "zsh", "zshrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+			},
+			cmd: importLinksCmd{force: true},
+			want: yamlData(config.Config{
+				Targets: []string{".vimrc", "zsh"},
+				Options: map[string]*config.Config{
+					"zsh": {
+						Flatten: true,
+						Targets: []string{"zshrc"},
+						Options: map[string]*config.Config{
+							"zshrc": {
+								Link: ".zshrc",
+							},
+						},
+					},
+				},
+				UseHome: internal.NewBool(true),
+			}),
+			perm: 0o600,
+			out:  "",
+			err:  nil,
+		},
+		{
+			name: "base directory",
+			home: map[string]fstest.File{
+				".local": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"share": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: map[string]fstest.File{
+								"nvim": {
+									Linkname: "",
+									Perm:     os.ModePerm,
+									Data:     nil,
+									Children: map[string]fstest.File{
+										"init.vim": {
+											Linkname: fstest.AbsPath("home", "dotfiles", "nvim", "init.vim"),
+											Perm:     os.ModePerm,
+											Data:     nil,
+											Children: nil,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: importLinksCmd{force: true},
+			want: yamlData(config.Config{
+				Targets: []string{"nvim"},
+				Options: map[string]*config.Config{
+					"nvim": {
+						Targets: []string{"init.vim"},
+						Options: map[string]*config.Config{
+							"init.vim": {
+								BaseDir: fstest.AbsPath("home", ".local", "share"),
+							},
+						},
+					},
+				},
+			}),
+			perm: 0o600,
+			out:  "",
+			err:  nil,
+		},
+		{
+			name: "skipped links",
+			home: map[string]fstest.File{
+				".gitignore": {
+					Linkname: filepath.Join("dotfiles", "git", "ignore"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				".missing": {
+					Linkname: fstest.AbsPath("home", "dotfiles", "missing"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				".vimrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", ".vimrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+				"config": {
+					Linkname: "",
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: map[string]fstest.File{
+						"nvim": {
+							Linkname: fstest.AbsPath("home", "dotfiles", "nvim"),
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: nil,
+						},
+						"vimrc": {
+							Linkname: fstest.AbsPath("home", "dotfiles", ".vimrc"),
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: nil,
+						},
+					},
+				},
+				"init.vim": {
+					Linkname: fstest.AbsPath("home", "dotfiles", "nvim", "init.vim"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+			},
+			cmd: importLinksCmd{force: true},
+			want: yamlData(config.Config{
+				Targets: []string{".vimrc", "nvim"},
+				Options: map[string]*config.Config{
+					".vimrc": {
+						Link: "vimrc",
+					},
+				},
+			}),
+			perm: 0o600,
+			out: "links: import: " + filepath.Join(fstest.AbsPath("home"), ".gitignore") + ": relative links are not supported\n" +
+				"links: import: " + filepath.Join(fstest.AbsPath("home"), ".missing") + ": target doesn't exist\n" +
+				"links: import: " + filepath.Join(fstest.AbsPath("home"), ".vimrc") + ": target is already imported from another link\n" +
+				"links: import: " + filepath.Join(fstest.AbsPath("home"), "init.vim") + ": target is already imported from another link\n",
+			err: nil,
+		},
+		{
+			name: "config exists",
+			home: map[string]fstest.File{
+				".vimrc": {
+					Linkname: fstest.AbsPath("home", "dotfiles", ".vimrc"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+			},
+			cmd:  importLinksCmd{},
+			want: []byte("targets: []\n"),
+			perm: 0o600,
+			out:  "",
+			err:  errConfigExists,
+		},
+		{
+			name: "no links",
+			home: map[string]fstest.File{
+				"hosts": {
+					Linkname: fstest.AbsPath("etc", "hosts"),
+					Perm:     os.ModePerm,
+					Data:     nil,
+					Children: nil,
+				},
+			},
+			cmd:  importLinksCmd{force: true},
+			want: []byte("targets: []\n"),
+			perm: 0o600,
+			out:  "",
+			err:  errNoLinks,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			home := copyFiles(tc.home)
+			home["dotfiles"] = fstest.File{
+				Linkname: "",
+				Perm:     os.ModePerm,
+				Data:     nil,
+				Children: copyFiles(dotfiles),
+			}
+			var (
+				drv = fstest.InMemoryDriver{
+					CurrentDir: "home/dotfiles",
+					Files: map[string]fstest.File{
+						"home": {
+							Linkname: "",
+							Perm:     os.ModePerm,
+							Data:     nil,
+							Children: home,
+						},
+					},
+				}
+				conf   = "TestImportLinks.yml"
+				appcfg = appConfig{
+					conf:          conf,
+					fs:            &drv,
+					getwd:         func() (string, error) { return fstest.AbsPath("home", "dotfiles"), nil },
+					userConfigDir: func() (string, error) { return fstest.AbsPath("home", "config"), nil },
+					userHomeDir:   func() (string, error) { return fstest.AbsPath("home"), nil },
+				}
+				exec = tc.cmd.register(appcfg.copy)
+				prg  = clitest.NewProgram("links")
+				err  = exec(prg)
+			)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.out, prg.CombinedOutput(); got != want {
+				t.Fatalf("\"import links\" command output mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			fi, err := drv.Stat(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tc.perm, fi.Perm(); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+			data, err := drv.ReadFile(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := string(tc.want), string(data); got != want {
+				t.Errorf("\"import links\" command created a wrong configuration file (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			if tc.err != nil {
+				return
+			}
+			// Every imported link must be reproduced by the configuration.
+			c, err := config.Load(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts, err := parseOptions(appcfg.copy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var p parser.Parser
+			tr, err := p.Parse(c, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := linker.New(fs.New(&drv)).Resolve(tr); err != nil {
+				t.Fatal(err)
+			}
+			tr.Walk(func(n *parser.Node) error {
+				if want, got := parser.StatusDone, n.Status; len(n.Children) == 0 && got != want {
+					t.Errorf("want %s to be %v, got %v", n.Target.FullPath(), want, got)
+				}
+				return nil
+			})
+		})
+	}
+}
@@ -23,13 +23,15 @@ func (cmd *initCmd) register(getcfg func() appConfig) func(cli.Program) error {
 			fs     = fs.New(appcfg.fs)
 		)
 		conf := appcfg.conf
-		fi, err := fs.Stat(conf)
-		if err != nil {
-			return err
-		}
-		fexists := fi.Exists()
-		if fexists && !cmd.force {
-			return errConfigExists
+		if !cmd.force {
+			// Fail before scanning anything.
+			fi, err := fs.Stat(conf)
+			if err != nil {
+				return err
+			}
+			if fi.Exists() {
+				return errConfigExists
+			}
 		}
 		cwd, err := appcfg.getwd()
 		if err != nil {
@@ -41,15 +43,31 @@ func (cmd *initCmd) register(getcfg func() appConfig) func(cli.Program) error {
 		}
 		cmd.read.exclude.Set(conf)
 		targets := cmd.read.resolve(files)
-		perm := os.FileMode(0o644)
-		if fexists {
-			perm = fi.Perm()
-		}
-		c := &config.Config{Targets: targets}
-		b, err := marshalYAML(c)
-		if err != nil {
+		if err := appcfg.ctx.Err(); err != nil {
 			return err
 		}
-		return fs.WriteFile(conf, b, perm)
+		return createConfig(fs, conf, &config.Config{Targets: targets}, cmd.force)
+	}
+}
+
+// createConfig writes c to a new configuration file. An existing file
+// is only overwritten when force is true, in which case its permissions are kept.
+func createConfig(fs fs.FileSystem, name string, c *config.Config, force bool) error {
+	fi, err := fs.Stat(name)
+	if err != nil {
+		return err
+	}
+	fexists := fi.Exists()
+	if fexists && !force {
+		return errConfigExists
+	}
+	perm := os.FileMode(0o644)
+	if fexists {
+		perm = fi.Perm()
+	}
+	b, err := marshalYAML(c)
+	if err != nil {
+		return err
 	}
+	return fs.WriteFile(name, b, perm)
 }
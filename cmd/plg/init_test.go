@@ -847,3 +847,39 @@ func TestInit(t *testing.T) {
 		})
 	}
 }
+
+func TestInitConfigExists(t *testing.T) {
+	var (
+		drv = fstest.InMemoryDriver{
+			CurrentDir: "home/dotfiles",
+			Files: map[string]fstest.File{
+				"home": {
+					Perm: os.ModePerm,
+					Children: map[string]fstest.File{
+						"dotfiles": {
+							Perm: os.ModePerm,
+							Children: map[string]fstest.File{
+								"pilgo.yml": {
+									Perm: os.ModePerm,
+									Data: []byte("targets: []\n"),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		appcfg = appConfig{
+			conf: "pilgo.yml",
+			fs:   &drv,
+			// The current directory is only needed for scanning.
+			getwd: func() (string, error) { return "", errors.New("getwd failed") },
+		}
+		cmd  initCmd
+		exec = cmd.register(appcfg.copy)
+		err  = exec(clitest.NewProgram("init"))
+	)
+	if want, got := errConfigExists, err; !errors.Is(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
@@ -6,56 +6,53 @@ import (
 
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/cli/cliutil"
-	"github.com/gbrlsnchs/pilgo/config"
 	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
 	"github.com/gbrlsnchs/pilgo/linker"
 	"github.com/gbrlsnchs/pilgo/parser"
-	"gopkg.in/yaml.v3"
 )
 
 type linkCmd struct{
-	tags cliutil.CommaSepOptionSet
+	tags     cliutil.CommaSepOptionSet
+	simulate bool
 }
 
 func (cmd *linkCmd) register(getcfg func() appConfig) func(cli.Program) error {
 	return func(prg cli.Program) error {
 		appcfg := getcfg()
 		exe := prg.Name()
-		fs := fs.New(appcfg.fs)
-		cwd, err := appcfg.getwd()
-		if err != nil {
-			return err
+		drv := appcfg.fs
+		if cmd.simulate {
+			drv = &fsutil.OverlayDriver{Lower: drv}
 		}
-		b, err := fs.ReadFile(appcfg.conf)
+		fs := fs.New(drv)
+		opts, err := parseOptions(appcfg)
 		if err != nil {
 			return err
 		}
-		c := new(config.Config)
-		if yaml.Unmarshal(b, c); err != nil {
-			return err
-		}
-		userConfigDir, err := appcfg.userConfigDir()
-		if err != nil {
-			return err
+		parse := func() (*parser.Tree, error) {
+			c, err := loadConfig(prg, fs, appcfg.conf)
+			if err != nil {
+				return nil, err
+			}
+			var p parser.Parser
+			tr, err := p.ParseContext(appcfg.ctx, c, append(opts, parser.Tags(cmd.tags))...)
+			if err != nil {
+				printCollisions(prg, err)
+				return nil, err
+			}
+			return tr, nil
 		}
-		homeConfigDir, err := appcfg.userHomeDir()
+		tr, err := parse()
 		if err != nil {
 			return err
 		}
-		var p parser.Parser
-		tr, err := p.Parse(c,
-			parser.BaseDirs(map[parser.Mode]string{
-				parser.UserMode: userConfigDir,
-				parser.HomeMode: homeConfigDir,
-			}),
-			parser.Cwd(cwd),
-			parser.Envsubst,
-			parser.Tags(cmd.tags))
-		if err != nil {
-			return err
+		ln := linker.New(fs, linkerOptions(prg, appcfg)...)
+		link := ln.LinkContext
+		if appcfg.readOnly && !cmd.simulate {
+			link = ln.ResolveContext // dry run
 		}
-		ln := linker.New(fs)
-		if err := ln.Link(tr); err != nil {
+		if err := link(appcfg.ctx, tr); err != nil {
 			var cft *linker.ConflictError
 			if errors.As(err, &cft) {
 				errw := prg.Stderr()
@@ -65,6 +62,32 @@ func (cmd *linkCmd) register(getcfg func() appConfig) func(cli.Program) error {
 			}
 			return err
 		}
+		if cmd.simulate {
+			// Linking has expanded tr, so a fresh tree is needed to check the result.
+			if tr, err = parse(); err != nil {
+				return err
+			}
+			return printSimulation(prg, appcfg, ln, tr)
+		}
 		return nil
 	}
 }
+
+// printSimulation resolves tr after it has been linked in memory and prints
+// what checking it would look like.
+func printSimulation(prg cli.Program, appcfg appConfig, ln *linker.Linker, tr *parser.Tree) error {
+	if err := ln.ResolveContext(appcfg.ctx, tr); err != nil {
+		return err
+	}
+	opts, err := printFlags{}.options(appcfg)
+	if err != nil {
+		return err
+	}
+	if err := printTree(prg, tr, formatText, opts...); err != nil {
+		return err
+	}
+	if s := summary(tr); s != "" {
+		fmt.Fprintf(prg.Stdout(), "\n%s\n", s)
+	}
+	return nil
+}
@@ -18,11 +18,12 @@ func TestLink(t *testing.T) {
 	os.Setenv("MY_ENV_VAR", "link.txt")
 	defer os.Unsetenv("MY_ENV_VAR")
 	testCases := []struct {
-		name string
-		drv  fstest.InMemoryDriver
-		cmd  linkCmd
-		want fstest.InMemoryDriver
-		err  error
+		name   string
+		drv    fstest.InMemoryDriver
+		cmd    linkCmd
+		want   fstest.InMemoryDriver
+		output string
+		err    error
 	}{
 		{
 			name: "default",
@@ -138,6 +139,230 @@ func TestLink(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "simulate",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"test": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     []byte("foo"),
+										Children: nil,
+									},
+									"link.txt": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     []byte("bar"),
+										Children: nil,
+									},
+									"simulate.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{
+												"$MY_ENV_VAR",
+												"test",
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+							"config": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: make(map[string]fstest.File, 0),
+							},
+						},
+					},
+				},
+			},
+			cmd: linkCmd{simulate: true},
+			want: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"test": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     []byte("foo"),
+										Children: nil,
+									},
+									"link.txt": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     []byte("bar"),
+										Children: nil,
+									},
+									"simulate.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{
+												"$MY_ENV_VAR",
+												"test",
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+							"config": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: make(map[string]fstest.File, 0),
+							},
+						},
+					},
+				},
+			},
+			output: `.
+├── link.txt <- ~/config/link.txt (DONE)
+└── test     <- ~/config/test     (DONE)
+
+2 done
+`,
+			err: nil,
+		},
+		{
+			name: "simulate_expand",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"dir": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"test": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     []byte("foo"),
+												Children: nil,
+											},
+										},
+									},
+									"simulate_expand.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"dir"},
+										}),
+										Children: nil,
+									},
+								},
+							},
+							"config": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"dir": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: make(map[string]fstest.File, 0),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			cmd: linkCmd{simulate: true},
+			want: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"dir": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: map[string]fstest.File{
+											"test": {
+												Linkname: "",
+												Perm:     os.ModePerm,
+												Data:     []byte("foo"),
+												Children: nil,
+											},
+										},
+									},
+									"simulate_expand.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{"dir"},
+										}),
+										Children: nil,
+									},
+								},
+							},
+							"config": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"dir": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data:     nil,
+										Children: make(map[string]fstest.File, 0),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			output: `.
+└── dir                           (EXPAND)
+    └── test <- ~/config/dir/test (DONE)
+
+1 done
+`,
+			err: nil,
+		},
 		{
 			name: "conflict",
 			drv: fstest.InMemoryDriver{
@@ -519,7 +744,7 @@ func TestLink(t *testing.T) {
 					t.Fatalf("want %v, got %v", want, got)
 				}
 			}
-			if want, got := "", prg.Output(); got != want {
+			if want, got := tc.output, prg.Output(); got != want {
 				t.Fatalf("\"link\" command output mismatch (-want +got):\n%s",
 					cmp.Diff(want, got))
 			}
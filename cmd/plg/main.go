@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
 
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/pilgo/cmd/internal"
@@ -11,26 +14,81 @@ import (
 )
 
 type appConfig struct {
+	ctx           context.Context
 	name          string
 	conf          string
 	fs            fs.Driver
 	getwd         func() (string, error)
 	userConfigDir func() (string, error)
 	userHomeDir   func() (string, error)
+	getenv        func(string) string
 	version       string
+	vars          varMap
+	noEnvsubst    bool
+	isTerminal    bool
+	root          absPath
+	readOnly      bool
+	journal       absPath
+	jobs          int
+	verbose       bool
 }
 
-func (cfg *appConfig) copy() appConfig { return *cfg }
+var errOutsideRoot = errors.New("current directory must be inside the root directory")
+
+func (cfg *appConfig) copy() appConfig {
+	c := *cfg
+	if c.ctx == nil {
+		c.ctx = context.Background()
+	}
+	if c.getenv == nil {
+		c.getenv = os.Getenv
+	}
+	if c.root != "" {
+		c.chroot()
+	}
+	if c.readOnly {
+		c.fs = fs.ReadOnly(c.fs)
+	} else if c.journal != "" {
+		cwd, _ := c.getwd() // commands fail by themselves if there's no current directory
+		c.fs = fsutil.JournalDriver{Inner: c.fs, Journal: appendFile(c.journal), Dir: cwd}
+	}
+	return c
+}
+
+// chroot confines the file system to the root directory, which becomes the
+// root of every path. The current directory must be inside the root directory.
+func (cfg *appConfig) chroot() {
+	drv := fsutil.RootedDriver{Root: string(cfg.root), Inner: cfg.fs}
+	cwd, err := cfg.getwd()
+	if err == nil {
+		if cwd, err = drv.Unroot(cwd); errors.Is(err, fsutil.ErrEscape) {
+			err = errOutsideRoot
+		}
+	}
+	if err != nil {
+		cfg.fs = errDriver{err}
+	} else {
+		drv.Dir = cwd
+		cfg.fs = drv
+	}
+	cfg.getwd = func() (string, error) { return cwd, err }
+}
 
 type rootCmd struct {
 	// store
-	check   checkCmd
-	config  configCmd
-	init    initCmd
-	link    linkCmd
-	scan    scanCmd
-	show    showCmd
-	version versionCmd
+	check    checkCmd
+	config   configCmd
+	export   exportCmd
+	imp      importCmd
+	init     initCmd
+	link     linkCmd
+	mv       mvCmd
+	rm       rmCmd
+	scan     scanCmd
+	show     showCmd
+	undo     undoCmd
+	validate validateCmd
+	version  versionCmd
 }
 
 func main() {
@@ -38,15 +96,33 @@ func main() {
 }
 
 func run() int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	go func() {
+		select {
+		case <-sigc:
+			// Let a second interrupt kill the program right away.
+			signal.Stop(sigc)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 	var (
 		root   rootCmd
+		code   int
 		appcfg = appConfig{
+			ctx:           ctx,
 			name:          "Pilgo",
-			fs:            fsutil.OSDriver{},
+			fs:            fsutil.OSDriver{}, // only configuration files are read, so CRLF is always normalized
 			getwd:         os.Getwd,
 			userConfigDir: os.UserConfigDir,
 			userHomeDir:   os.UserHomeDir,
+			getenv:        os.Getenv,
 			version:       internal.Version(),
+			isTerminal:    isTerminal(os.Stdout),
 		}
 	)
 	cli := cli.New(&cli.Command{
@@ -59,6 +135,53 @@ func run() int {
 				DefValue:  config.DefaultName,
 				Recipient: &appcfg.conf,
 			},
+			"var": cli.VarOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Set a variable, overriding the configuration file. Repeat option to set more variables.",
+					ArgLabel:    "NAME=VALUE",
+				},
+				Recipient: &appcfg.vars,
+			},
+			"no-envsubst": cli.BoolOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Don't replace variables with environment variables.",
+				},
+				Recipient: &appcfg.noEnvsubst,
+			},
+			"read-only": cli.BoolOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Don't modify any files. Commands that would modify files fail, except for link, which only checks whether links can be created.",
+				},
+				Recipient: &appcfg.readOnly,
+			},
+			"journal": cli.VarOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Record changes to files in a journal, so they can be reverted with undo.",
+					ArgLabel:    "FILE",
+				},
+				Recipient: &appcfg.journal,
+			},
+			"verbose": cli.BoolOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Print what is done to targets as it happens, like resolving them and creating symlinks.",
+					Short:       'v',
+				},
+				Recipient: &appcfg.verbose,
+			},
+			"jobs": cli.IntOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Resolve up to N targets at the same time, which speeds up slow file systems. Zero means the number of CPUs.",
+					ArgLabel:    "N",
+				},
+				Recipient: &appcfg.jobs,
+			},
+			"root": cli.VarOption{
+				OptionDetails: cli.OptionDetails{
+					Description: "Use a directory as the root of the file system, so nothing outside it is touched. The current directory must be inside it.",
+					ArgLabel:    "DIR",
+				},
+				Recipient: &appcfg.root,
+			},
 		},
 		Subcommands: map[string]*cli.Command{
 			"check": {
@@ -67,7 +190,7 @@ func run() int {
 					"fail": cli.BoolOption{
 						OptionDetails: cli.OptionDetails{
 							Short:       'f',
-							Description: "Return an error if there are any conflicts.",
+							Description: "Return an error if not all targets are linked. The exit code tells the worst status found.",
 						},
 						DefValue:  false,
 						Recipient: &root.check.fail,
@@ -80,8 +203,54 @@ func run() int {
 						},
 						Recipient: &root.check.tags,
 					},
+					"format": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Output format, which is one of text, json, yaml, dot or mermaid.",
+							ArgLabel:    "FORMAT",
+						},
+						DefValue:  formatText,
+						Recipient: &root.check.format,
+					},
+					"color": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Colorize the output, which is one of auto, always or never. In auto mode, the output is colorized if it's a terminal and NO_COLOR is not set.",
+							ArgLabel:    "WHEN",
+						},
+						DefValue:  colorAuto,
+						Recipient: &root.check.print.color,
+					},
+					"style": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Tree style, which is one of unicode, ascii or indent.",
+							ArgLabel:    "STYLE",
+						},
+						DefValue:  styleUnicode,
+						Recipient: &root.check.print.style,
+					},
+					"depth": cli.IntOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Maximum depth of the tree. Truncated targets tell how many targets are hidden below them. Zero means no limit.",
+							ArgLabel:    "N",
+						},
+						Recipient: &root.check.print.depth,
+					},
+					"paths": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "How links are printed, which is one of home, relative or absolute. Home abbreviates the home directory to \"~\" and relative prints links relative to the configuration directory.",
+							ArgLabel:    "MODE",
+						},
+						DefValue:  pathsHome,
+						Recipient: &root.check.print.paths,
+					},
+					"only": cli.VarOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Comma-separated list of statuses. Only targets with these statuses, and their parents, are printed.",
+							ArgLabel:    "STATUS 1,...,STATUS n",
+						},
+						Recipient: &root.check.only,
+					},
 				},
-				Exec: root.check.register(appcfg.copy),
+				Exec: withExitCode(root.check.register(appcfg.copy), &code),
 			},
 			"config": {
 				Description: "Configure a dotfile in the configuration file.",
@@ -94,6 +263,14 @@ func run() int {
 						},
 						Recipient: &root.config.baseDir,
 					},
+					"base": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Set the target's base by name (config, home, data, state, cache, bin or a root). Works recursively for all nested targets, unless overridden.",
+							ArgLabel:    "NAME",
+							Short:       'B',
+						},
+						Recipient: &root.config.base,
+					},
 					"link": cli.StringOption{
 						OptionDetails: cli.OptionDetails{
 							Description: "Set the target's link name.",
@@ -130,7 +307,65 @@ func run() int {
 					Required:  false,
 					Recipient: &root.config.file,
 				},
-				Exec: root.config.register(appcfg.copy),
+				Exec: withExitCode(root.config.register(appcfg.copy), &code),
+			},
+			"export": {
+				Description: "Export a script that links your dotfiles without Pilgo.",
+				Options: map[string]cli.Option{
+					"format": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Script format, which is currently only sh, for a POSIX shell script.",
+							ArgLabel:    "FORMAT",
+						},
+						DefValue:  formatSh,
+						Recipient: &root.export.format,
+					},
+					"tags": cli.VarOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Comma-separated list of tags. Targets with these tags will also be exported.",
+							Short:       't',
+							ArgLabel:    "TAG 1,...,TAG n",
+						},
+						Recipient: &root.export.tags,
+					},
+				},
+				Exec: withExitCode(root.export.register(appcfg.copy), &code),
+			},
+			"import": {
+				Description: "Create a configuration file from dotfiles managed by other means.",
+				Subcommands: map[string]*cli.Command{
+					"links": {
+						Description: "Import existing symlinks to the current directory.",
+						Options: map[string]cli.Option{
+							"force": cli.BoolOption{
+								OptionDetails: cli.OptionDetails{
+									Description: "Overwrite the existing configuration file.",
+									Short:       'f',
+								},
+								Recipient: &root.imp.links.force,
+							},
+						},
+						Exec: withExitCode(root.imp.links.register(appcfg.copy), &code),
+					},
+					"stow": {
+						Description: "Import GNU Stow packages from the current directory.",
+						Options: map[string]cli.Option{
+							"force": cli.BoolOption{
+								OptionDetails: cli.OptionDetails{
+									Description: "Overwrite the existing configuration file.",
+									Short:       'f',
+								},
+								Recipient: &root.imp.stow.force,
+							},
+						},
+						Arg: cli.RepeatingArg{
+							Label:     "PACKAGE",
+							Required:  true,
+							Recipient: &root.imp.stow.packages,
+						},
+						Exec: withExitCode(root.imp.stow.register(appcfg.copy), &code),
+					},
+				},
 			},
 			"init": {
 				Description: "Initialize a configuration file.",
@@ -164,11 +399,11 @@ func run() int {
 						Recipient: &root.init.read.hidden,
 					},
 				},
-				Exec: root.init.register(appcfg.copy),
+				Exec: withExitCode(root.init.register(appcfg.copy), &code),
 			},
 			"link": {
 				Description: "Link your dotfiles as set in the configuration file.",
-				Exec:        root.link.register(appcfg.copy),
+				Exec:        withExitCode(root.link.register(appcfg.copy), &code),
 				Options: map[string]cli.Option{
 					"tags": cli.VarOption{
 						OptionDetails: cli.OptionDetails{
@@ -178,7 +413,53 @@ func run() int {
 						},
 						Recipient: &root.link.tags,
 					},
+					"simulate": cli.BoolOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Link in memory only, without touching the file system, and print what checking would show afterwards.",
+							Short:       's',
+						},
+						Recipient: &root.link.simulate,
+					},
+				},
+			},
+			"mv": {
+				Description: "Rename a target in the configuration file.",
+				Options: map[string]cli.Option{
+					"rename": cli.BoolOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Also rename the target file.",
+							Short:       'r',
+						},
+						Recipient: &root.mv.rename,
+					},
+					"relink": cli.BoolOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Replace the target's existing symlinks with new ones.",
+							Short:       'l',
+						},
+						Recipient: &root.mv.relink,
+					},
 				},
+				Arg: cli.StringArg{
+					Label:     "OLD",
+					Required:  true,
+					Recipient: &root.mv.src,
+					Next: cli.StringArg{
+						Label:     "NEW",
+						Required:  true,
+						Recipient: &root.mv.dst,
+					},
+				},
+				Exec: withExitCode(root.mv.register(appcfg.copy), &code),
+			},
+			"rm": {
+				Description: "Remove a target from the configuration file.",
+				Arg: cli.StringArg{
+					Label:     "TARGET",
+					Required:  true,
+					Recipient: &root.rm.file,
+				},
+				Exec: withExitCode(root.rm.register(appcfg.copy), &code),
 			},
 			"scan": {
 				Description: "Set targets by scanning a directory.",
@@ -210,11 +491,11 @@ func run() int {
 					Required:  false,
 					Recipient: &root.scan.file,
 				},
-				Exec: root.scan.register(appcfg.copy),
+				Exec: withExitCode(root.scan.register(appcfg.copy), &code),
 			},
 			"show": {
 				Description: "Show your dotfiles in a tree view.",
-				Exec:        root.show.register(appcfg.copy),
+				Exec:        withExitCode(root.show.register(appcfg.copy), &code),
 				Options: map[string]cli.Option{
 					"tags": cli.VarOption{
 						OptionDetails: cli.OptionDetails{
@@ -224,13 +505,64 @@ func run() int {
 						},
 						Recipient: &root.show.tags,
 					},
+					"format": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Output format, which is one of text, json, yaml, dot or mermaid.",
+							ArgLabel:    "FORMAT",
+						},
+						DefValue:  formatText,
+						Recipient: &root.show.format,
+					},
+					"color": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Colorize the output, which is one of auto, always or never. In auto mode, the output is colorized if it's a terminal and NO_COLOR is not set.",
+							ArgLabel:    "WHEN",
+						},
+						DefValue:  colorAuto,
+						Recipient: &root.show.print.color,
+					},
+					"style": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Tree style, which is one of unicode, ascii or indent.",
+							ArgLabel:    "STYLE",
+						},
+						DefValue:  styleUnicode,
+						Recipient: &root.show.print.style,
+					},
+					"depth": cli.IntOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "Maximum depth of the tree. Truncated targets tell how many targets are hidden below them. Zero means no limit.",
+							ArgLabel:    "N",
+						},
+						Recipient: &root.show.print.depth,
+					},
+					"paths": cli.StringOption{
+						OptionDetails: cli.OptionDetails{
+							Description: "How links are printed, which is one of home, relative or absolute. Home abbreviates the home directory to \"~\" and relative prints links relative to the configuration directory.",
+							ArgLabel:    "MODE",
+						},
+						DefValue:  pathsHome,
+						Recipient: &root.show.print.paths,
+					},
 				},
 			},
+			"undo": {
+				Description: "Revert changes recorded in the journal, from the newest to the oldest one.",
+				Exec:        withExitCode(root.undo.register(appcfg.copy), &code),
+			},
+			"validate": {
+				Description: "Validate the configuration file.",
+				Exec:        withExitCode(root.validate.register(appcfg.copy), &code),
+			},
 			"version": {
 				Description: "Print version.",
-				Exec:        root.version.register(appcfg.copy),
+				Exec:        withExitCode(root.version.register(appcfg.copy), &code),
 			},
 		},
 	})
-	return cli.ParseAndRun(os.Args)
+	status := cli.ParseAndRun(os.Args)
+	if code != 0 {
+		return code
+	}
+	return status
 }
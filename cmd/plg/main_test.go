@@ -11,7 +11,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gbrlsnchs/pilgo/fs"
 	cmdtest "github.com/google/go-cmdtest"
+	"github.com/google/go-cmp/cmp"
 )
 
 const failureStatus = 0xDEADC0DE // 3735929054
@@ -24,7 +26,18 @@ func TestCLI(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	// Tests may change where the home and configuration directories are.
+	env := make(map[string]string)
+	for _, name := range []string{"HOME", "XDG_CONFIG_HOME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
 	ts.Setup = func(rootdir string) error {
+		os.Unsetenv("XDG_CONFIG_HOME")
+		for name, value := range env {
+			os.Setenv(name, value)
+		}
 		if runtime.GOOS == "darwin" {
 			// XXX: Fix "/private${ROOTDIR}" being printed when on macOS.
 			// This way, it is possible to unify Unix tests.
@@ -68,3 +81,112 @@ func cpCmd(t *testing.T, testdata string) func([]string, string) ([]byte, error)
 	}
 
 }
+
+// runCLI runs plg with args from dir and returns its exit status and combined output.
+func runCLI(t *testing.T, dir string, args ...string) (int, string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	out, err := ioutil.TempFile("", "pilgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	stdout, stderr, osArgs := os.Stdout, os.Stderr, os.Args
+	os.Stdout, os.Stderr, os.Args = out, out, append([]string{"plg"}, args...)
+	status := run()
+	os.Stdout, os.Stderr, os.Args = stdout, stderr, osArgs
+	b, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, string(b)
+}
+
+func TestRootFlag(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("absolute paths without a volume name are relative on Windows")
+	}
+	tmp, err := ioutil.TempDir("", "pilgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	var (
+		root     = filepath.Join(tmp, "root")
+		dotfiles = filepath.Join(root, "dotfiles")
+		links    = filepath.Join(tmp, "links") // only inside root
+	)
+	if err := os.MkdirAll(dotfiles, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	conf := fmt.Sprintf("baseDir: %s\ntargets:\n- test\n", links)
+	if err := ioutil.WriteFile(filepath.Join(dotfiles, "pilgo.yml"), []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dotfiles, "test"), []byte("test"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if status, out := runCLI(t, dotfiles, "-root", root, "link"); status != 0 {
+		t.Fatalf("want exit status 0, got %d:\n%s", status, out)
+	}
+	if _, err := os.Lstat(links); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+	}
+	linkname, err := os.Readlink(filepath.Join(root, links, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := filepath.Join(dotfiles, "test"), linkname; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestReadOnlyFlag(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "pilgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	var (
+		links = filepath.Join(tmp, "links")
+		conf  = filepath.Join(tmp, "pilgo.yml")
+		data  = []byte(fmt.Sprintf("baseDir: %s\ntargets:\n- test\n", links))
+	)
+	if err := ioutil.WriteFile(conf, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, "test"), []byte("test"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("link", func(t *testing.T) {
+		if status, out := runCLI(t, tmp, "-read-only", "link"); status != 0 {
+			t.Fatalf("want exit status 0, got %d:\n%s", status, out)
+		}
+		if _, err := os.Lstat(links); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+		}
+	})
+	t.Run("config", func(t *testing.T) {
+		status, out := runCLI(t, tmp, "-read-only", "config", "-flatten", "test")
+		if want, got := 1, status; got != want {
+			t.Fatalf("want exit status %d, got %d:\n%s", want, got, out)
+		}
+		if want, got := fs.ErrReadOnly.Error(), out; !strings.Contains(got, want) {
+			t.Fatalf("want output to contain %q, got %q", want, got)
+		}
+		b, err := ioutil.ReadFile(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := string(data), string(b); got != want {
+			t.Fatalf("want configuration to be kept (-want +got):\n%s", cmp.Diff(want, got))
+		}
+	})
+}
@@ -2,10 +2,51 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/parser"
 	"gopkg.in/yaml.v3"
 )
 
+// Output formats for trees.
+const (
+	formatText    = "text"
+	formatJSON    = "json"
+	formatYAML    = "yaml"
+	formatDot     = "dot"
+	formatMermaid = "mermaid"
+)
+
+// Color modes for trees printed as text.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// Styles for trees printed as text.
+const (
+	styleUnicode = "unicode"
+	styleASCII   = "ascii"
+	styleIndent  = "indent"
+)
+
+// Path modes for links in trees printed as text.
+const (
+	pathsHome     = "home"
+	pathsRelative = "relative"
+	pathsAbsolute = "absolute"
+)
+
 func marshalYAML(v interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := yaml.NewEncoder(&buf)
@@ -15,3 +56,236 @@ func marshalYAML(v interface{}) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// loadConfig reads and validates a configuration file. Validation problems
+// are printed to the program's stderr, prefixed by the file's name.
+func loadConfig(prg cli.Program, fs fs.FileSystem, name string) (*config.Config, error) {
+	b, err := fs.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := config.Load(b)
+	if err != nil {
+		var verr *config.ValidationError
+		if !errors.As(err, &verr) {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		exe := prg.Name()
+		errw := prg.Stderr()
+		for _, err := range verr.Errs {
+			fmt.Fprintf(errw, "%s: %s:%v\n", exe, name, err)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// baseDirs returns the base directory for every parser mode. XDG directories
+// fall back to their default locations inside the home directory.
+func baseDirs(appcfg appConfig) (map[parser.Mode]string, error) {
+	userConfigDir, err := appcfg.userConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	home, err := appcfg.userHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	xdg := func(env string, elem ...string) string {
+		// Relative paths are invalid according to the XDG specification.
+		if dir := appcfg.getenv(env); filepath.IsAbs(dir) {
+			return dir
+		}
+		return filepath.Join(append([]string{home}, elem...)...)
+	}
+	return map[parser.Mode]string{
+		parser.UserMode:  userConfigDir,
+		parser.HomeMode:  home,
+		parser.DataMode:  xdg("XDG_DATA_HOME", ".local", "share"),
+		parser.StateMode: xdg("XDG_STATE_HOME", ".local", "state"),
+		parser.CacheMode: xdg("XDG_CACHE_HOME", ".cache"),
+		parser.BinMode:   xdg("XDG_BIN_HOME", ".local", "bin"),
+	}, nil
+}
+
+// parseOptions returns the options every command uses for parsing the configuration.
+func parseOptions(appcfg appConfig) ([]parser.ParseOption, error) {
+	dirs, err := baseDirs(appcfg)
+	if err != nil {
+		return nil, err
+	}
+	cwd, err := appcfg.getwd()
+	if err != nil {
+		return nil, err
+	}
+	opts := []parser.ParseOption{
+		parser.BaseDirs(dirs),
+		parser.Cwd(cwd),
+		parser.Vars(appcfg.vars),
+	}
+	if !appcfg.noEnvsubst {
+		opts = append(opts, parser.Envsubst)
+	}
+	return opts, nil
+}
+
+// checkFormat returns an error if format is not a valid output format.
+func checkFormat(format string) error {
+	switch format {
+	case "", formatText, formatJSON, formatYAML, formatDot, formatMermaid:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// printFlags holds the options for printing trees as text.
+type printFlags struct {
+	color string
+	style string
+	depth int
+	paths string
+}
+
+// options returns the parser's options for printing trees as text. In auto
+// mode, colors are only used when stdout is a terminal and NO_COLOR is not set.
+func (pf printFlags) options(appcfg appConfig) ([]parser.PrintOption, error) {
+	var opts []parser.PrintOption
+	switch pf.color {
+	case "", colorAuto:
+		if appcfg.isTerminal && appcfg.getenv("NO_COLOR") == "" {
+			opts = append(opts, parser.Colors)
+		}
+	case colorAlways:
+		opts = append(opts, parser.Colors)
+	case colorNever:
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", pf.color)
+	}
+	switch pf.style {
+	case "", styleUnicode:
+	case styleASCII:
+		opts = append(opts, parser.ASCIIStyle)
+	case styleIndent:
+		opts = append(opts, parser.IndentStyle)
+	default:
+		return nil, fmt.Errorf("unknown style %q", pf.style)
+	}
+	switch pf.paths {
+	case "", pathsHome:
+		home, err := appcfg.userHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.HomePaths(home))
+	case pathsRelative:
+		opts = append(opts, parser.RelativePaths)
+	case pathsAbsolute:
+	default:
+		return nil, fmt.Errorf("unknown path mode %q", pf.paths)
+	}
+	if pf.depth < 0 {
+		return nil, fmt.Errorf("invalid depth %d", pf.depth)
+	}
+	return append(opts, parser.Depth(pf.depth)), nil
+}
+
+// printTree prints tr to the program's stdout in the given format.
+// Options are only used when printing it as text or as a graph.
+func printTree(prg cli.Program, tr *parser.Tree, format string, opts ...parser.PrintOption) error {
+	w := prg.Stdout()
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tr)
+	case formatYAML:
+		b, err := marshalYAML(tr)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case formatDot:
+		return tr.WriteDot(w, opts...)
+	case formatMermaid:
+		return tr.WriteMermaid(w, opts...)
+	}
+	return tr.Print(w, opts...)
+}
+
+// printCollisions prints every link collision in err, if any, to the program's stderr.
+func printCollisions(prg cli.Program, err error) {
+	var cerr *parser.CollisionError
+	if !errors.As(err, &cerr) {
+		return
+	}
+	exe := prg.Name()
+	errw := prg.Stderr()
+	for _, err := range cerr.Collisions {
+		fmt.Fprintf(errw, "%s: %v\n", exe, err)
+	}
+}
+
+type varMap map[string]string
+
+func (vm *varMap) Set(value string) error {
+	i := strings.IndexByte(value, '=')
+	if i <= 0 {
+		return fmt.Errorf("invalid variable %q: want NAME=VALUE", value)
+	}
+	if *vm == nil {
+		*vm = make(varMap)
+	}
+	(*vm)[value[:i]] = value[i+1:]
+	return nil
+}
+
+func (vm varMap) String() string {
+	vars := make([]string, 0, len(vm))
+	for name, value := range vm {
+		vars = append(vars, name+"="+value)
+	}
+	sort.Strings(vars)
+	return strings.Join(vars, ",")
+}
+
+// absPath is a path that is made absolute when set.
+type absPath string
+
+func (p *absPath) Set(value string) error {
+	abs, err := filepath.Abs(value)
+	if err != nil {
+		return err
+	}
+	*p = absPath(abs)
+	return nil
+}
+
+func (p absPath) String() string { return string(p) }
+
+// appendFile is a file that is opened for appending on every write.
+type appendFile string
+
+func (name appendFile) Write(p []byte) (int, error) {
+	f, err := os.OpenFile(string(name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.Write(p)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// errDriver is a driver that always fails with the same error.
+type errDriver struct{ err error }
+
+func (drv errDriver) MkdirAll(_ string) error                           { return drv.err }
+func (drv errDriver) ReadDir(_ string) ([]fs.FileInfo, error)           { return nil, drv.err }
+func (drv errDriver) ReadFile(_ string) ([]byte, error)                 { return nil, drv.err }
+func (drv errDriver) Remove(_ string) error                             { return drv.err }
+func (drv errDriver) Rename(_, _ string) error                          { return drv.err }
+func (drv errDriver) Stat(_ string) (fs.FileInfo, error)                { return nil, drv.err }
+func (drv errDriver) Symlink(_, _ string) error                         { return drv.err }
+func (drv errDriver) WriteFile(_ string, _ []byte, _ os.FileMode) error { return drv.err }
@@ -3,8 +3,13 @@ package main
 import (
 	"io/ioutil"
 	"path/filepath"
+	"strings"
+	"testing"
 
 	"github.com/andybalholm/crlf"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/gbrlsnchs/pilgo/parser"
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/text/transform"
 )
 
@@ -30,3 +35,87 @@ func yamlData(v interface{}) []byte {
 	}
 	return b
 }
+
+func TestBaseDirs(t *testing.T) {
+	dataHome, err := filepath.Abs("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"XDG_DATA_HOME":  dataHome,
+		"XDG_STATE_HOME": "state", // relative paths are ignored
+	}
+	appcfg := appConfig{
+		userConfigDir: func() (string, error) { return fstest.AbsPath("home", "config"), nil },
+		userHomeDir:   func() (string, error) { return fstest.AbsPath("home"), nil },
+		getenv:        func(key string) string { return env[key] },
+	}
+	dirs, err := baseDirs(appcfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[parser.Mode]string{
+		parser.UserMode:  fstest.AbsPath("home", "config"),
+		parser.HomeMode:  fstest.AbsPath("home"),
+		parser.DataMode:  dataHome,
+		parser.StateMode: fstest.AbsPath("home", ".local", "state"),
+		parser.CacheMode: fstest.AbsPath("home", ".cache"),
+		parser.BinMode:   fstest.AbsPath("home", ".local", "bin"),
+	}
+	if got := dirs; !cmp.Equal(got, want) {
+		t.Fatalf("baseDirs mismatch (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestPrintFlagsColors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		flags   printFlags
+		colored bool
+	}{
+		{
+			name:    "auto",
+			env:     nil,
+			flags:   printFlags{color: colorAuto},
+			colored: true,
+		},
+		{
+			name:    "auto NO_COLOR",
+			env:     map[string]string{"NO_COLOR": "1"},
+			flags:   printFlags{color: colorAuto},
+			colored: false,
+		},
+		{
+			name:    "always NO_COLOR",
+			env:     map[string]string{"NO_COLOR": "1"},
+			flags:   printFlags{color: colorAlways},
+			colored: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appcfg := appConfig{
+				userHomeDir: func() (string, error) { return fstest.AbsPath("home"), nil },
+				getenv:      func(key string) string { return tc.env[key] },
+				isTerminal:  true,
+			}
+			opts, err := tc.flags.options(appcfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr := &parser.Tree{Root: &parser.Node{Children: []*parser.Node{{
+				Target: parser.File{BaseDir: "dotfiles", Path: []string{"foo"}},
+				Link:   parser.File{BaseDir: "config", Path: []string{"foo"}},
+				Status: parser.StatusReady,
+			}}}}
+			var bd strings.Builder
+			if err := tr.Print(&bd, opts...); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tc.colored, strings.Contains(bd.String(), "\x1b["); got != want {
+				t.Fatalf("want colored output to be %t, got %t:\n%s", want, got, bd.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+	"github.com/gbrlsnchs/pilgo/linker"
+	"github.com/gbrlsnchs/pilgo/parser"
+)
+
+type mvCmd struct {
+	src    string
+	dst    string
+	rename bool
+	relink bool
+}
+
+func (cmd *mvCmd) register(getcfg func() appConfig) cli.ExecFunc {
+	return func(prg cli.Program) error {
+		appcfg := getcfg()
+		src, dst := filepath.Clean(cmd.src), filepath.Clean(cmd.dst)
+		fs := fs.New(appcfg.fs)
+		c, err := loadConfig(prg, fs, appcfg.conf)
+		if err != nil {
+			return err
+		}
+		// Parsing modifies the configuration, so copies of it are parsed instead.
+		b, err := marshalYAML(c)
+		if err != nil {
+			return err
+		}
+		var links []string
+		if cmd.relink {
+			tr, err := cmd.parse(appcfg, b, src)
+			if err != nil {
+				printCollisions(prg, err)
+				return err
+			}
+			ln := linker.New(fs, linkerOptions(prg, appcfg)...)
+			if err := ln.ResolveContext(appcfg.ctx, tr); err != nil {
+				printConflicts(prg, err)
+				return err
+			}
+			tr.Walk(func(n *parser.Node) error {
+				if n.Status == parser.StatusDone {
+					links = append(links, n.Link.FullPath())
+				}
+				return nil
+			})
+		}
+		if err := c.Rename(src, dst); err != nil {
+			return err
+		}
+		if b, err = marshalYAML(c); err != nil {
+			return err
+		}
+		if cmd.relink {
+			// Move everything in memory first, so nothing is changed if the
+			// new target can't be linked.
+			ovl := fsutil.OverlayDriver{Lower: appcfg.fs}
+			if err := cmd.move(prg, appcfg, b, links, &ovl, linker.Jobs(appcfg.jobs)); err != nil {
+				return err
+			}
+		}
+		return cmd.move(prg, appcfg, b, links, appcfg.fs, linkerOptions(prg, appcfg)...)
+	}
+}
+
+// move applies the changes of moving a target to drv: it renames the target's file,
+// if needed, removes its old links, writes the configuration in b and then links the
+// new target, if needed.
+func (cmd *mvCmd) move(prg cli.Program, appcfg appConfig, b []byte, links []string, drv fs.Driver, opts ...linker.Option) error {
+	fs := fs.New(drv)
+	src, dst := filepath.Clean(cmd.src), filepath.Clean(cmd.dst)
+	if cmd.rename {
+		cwd, err := appcfg.getwd()
+		if err != nil {
+			return err
+		}
+		if err := fs.Rename(filepath.Join(cwd, src), filepath.Join(cwd, dst)); err != nil {
+			return err
+		}
+	}
+	for _, link := range links {
+		if err := fs.Remove(link); err != nil {
+			return err
+		}
+	}
+	fi, err := fs.Stat(appcfg.conf)
+	if err != nil {
+		return err
+	}
+	if err := fs.WriteFile(appcfg.conf, b, fi.Perm()); err != nil {
+		return err
+	}
+	if !cmd.relink {
+		return nil
+	}
+	tr, err := cmd.parse(appcfg, b, dst)
+	if err != nil {
+		printCollisions(prg, err)
+		return err
+	}
+	ln := linker.New(fs, opts...)
+	if err := ln.LinkContext(appcfg.ctx, tr); err != nil {
+		printConflicts(prg, err)
+		return err
+	}
+	return nil
+}
+
+// printConflicts prints every conflict in err, if it's a *linker.ConflictError.
+func printConflicts(prg cli.Program, err error) {
+	var cft *linker.ConflictError
+	if errors.As(err, &cft) {
+		exe := prg.Name()
+		errw := prg.Stderr()
+		for _, err := range cft.Errs {
+			fmt.Fprintf(errw, "%s: %v\n", exe, err)
+		}
+	}
+}
+
+// parse parses the configuration in b and returns a tree containing only the target in path.
+func (cmd *mvCmd) parse(appcfg appConfig, b []byte, path string) (*parser.Tree, error) {
+	c, err := config.Load(b)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(appcfg)
+	if err != nil {
+		return nil, err
+	}
+	var p parser.Parser
+	tr, err := p.ParseContext(appcfg.ctx, c, append(opts, parser.Tags(allTags(c)))...)
+	if err != nil {
+		return nil, err
+	}
+	subtree := &parser.Tree{Root: new(parser.Node)}
+	tr.Walk(func(n *parser.Node) error {
+		if filepath.Join(n.Target.Path...) == path {
+			subtree.Root.Children = []*parser.Node{n}
+		}
+		return nil
+	})
+	if len(subtree.Root.Children) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, config.ErrTargetNotExist)
+	}
+	return subtree, nil
+}
+
+// allTags collects tags from all targets, so tagged targets are not filtered out.
+func allTags(c *config.Config) map[string]struct{} {
+	tags := make(map[string]struct{})
+	var collect func(*config.Config)
+	collect = func(c *config.Config) {
+		for _, t := range c.Tags {
+			tags[t] = struct{}{}
+		}
+		for _, cc := range c.Options {
+			collect(cc)
+		}
+	}
+	collect(c)
+	return tags
+}
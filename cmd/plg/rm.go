@@ -0,0 +1,36 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+type rmCmd struct {
+	file string
+}
+
+func (cmd *rmCmd) register(getcfg func() appConfig) cli.ExecFunc {
+	return func(prg cli.Program) error {
+		appcfg := getcfg()
+		fs := fs.New(appcfg.fs)
+		conf := appcfg.conf
+		c, err := loadConfig(prg, fs, conf)
+		if err != nil {
+			return err
+		}
+		if err := c.Remove(filepath.Clean(cmd.file)); err != nil {
+			return err
+		}
+		b, err := marshalYAML(c)
+		if err != nil {
+			return err
+		}
+		fi, err := fs.Stat(conf)
+		if err != nil {
+			return err
+		}
+		return fs.WriteFile(conf, b, fi.Perm())
+	}
+}
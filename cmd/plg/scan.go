@@ -3,7 +3,7 @@ package main
 import (
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/pilgo/config"
-	"gopkg.in/yaml.v3"
+	"github.com/gbrlsnchs/pilgo/fs"
 )
 
 type scanCmd struct {
@@ -12,11 +12,11 @@ type scanCmd struct {
 }
 
 func (cmd *scanCmd) register(getcfg func() appConfig) cli.ExecFunc {
-	return func(_ cli.Program) error {
+	return func(prg cli.Program) error {
 		appcfg := getcfg()
-		fs := appcfg.fs
+		fs := fs.New(appcfg.fs)
 		conf := appcfg.conf
-		b, err := fs.ReadFile(conf)
+		c, err := loadConfig(prg, fs, conf)
 		if err != nil {
 			return err
 		}
@@ -26,13 +26,13 @@ func (cmd *scanCmd) register(getcfg func() appConfig) cli.ExecFunc {
 		}
 		cmd.read.exclude.Set(conf)
 		targets := cmd.read.resolve(files)
-		var c config.Config
-		if err := yaml.Unmarshal(b, &c); err != nil {
-			return err
-		}
 		cc := &config.Config{Targets: targets}
 		c.Set(cmd.file, cc, config.ModeScan)
-		if b, err = marshalYAML(c); err != nil {
+		b, err := marshalYAML(c)
+		if err != nil {
+			return err
+		}
+		if err := appcfg.ctx.Err(); err != nil {
 			return err
 		}
 		fi, err := fs.Stat(conf)
@@ -1,57 +1,43 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/gbrlsnchs/cli"
 	"github.com/gbrlsnchs/cli/cliutil"
-	"github.com/gbrlsnchs/pilgo/config"
 	"github.com/gbrlsnchs/pilgo/fs"
 	"github.com/gbrlsnchs/pilgo/parser"
-	"gopkg.in/yaml.v3"
 )
 
-type showCmd struct{
-	tags cliutil.CommaSepOptionSet
+type showCmd struct {
+	tags   cliutil.CommaSepOptionSet
+	format string
+	print  printFlags
 }
 
 func (cmd *showCmd) register(getcfg func() appConfig) func(cli.Program) error {
 	return func(prg cli.Program) error {
-		appcfg := getcfg()
-		fs := fs.New(appcfg.fs)
-		b, err := fs.ReadFile(appcfg.conf)
-		if err != nil {
+		if err := checkFormat(cmd.format); err != nil {
 			return err
 		}
-		c := new(config.Config)
-		if yaml.Unmarshal(b, c); err != nil {
-			return err
-		}
-		userConfigDir, err := appcfg.userConfigDir()
+		appcfg := getcfg()
+		popts, err := cmd.print.options(appcfg)
 		if err != nil {
 			return err
 		}
-		homeConfigDir, err := appcfg.userHomeDir()
+		fs := fs.New(fs.ReadOnly(appcfg.fs))
+		c, err := loadConfig(prg, fs, appcfg.conf)
 		if err != nil {
 			return err
 		}
-		cwd, err := appcfg.getwd()
+		opts, err := parseOptions(appcfg)
 		if err != nil {
 			return err
 		}
 		var p parser.Parser
-		tr, err := p.Parse(c,
-			parser.BaseDirs(map[parser.Mode]string{
-				parser.UserMode: userConfigDir,
-				parser.HomeMode: homeConfigDir,
-			}),
-			parser.Cwd(cwd),
-			parser.Envsubst,
-			parser.Tags(cmd.tags))
+		tr, err := p.ParseContext(appcfg.ctx, c, append(opts, parser.Tags(cmd.tags))...)
 		if err != nil {
+			printCollisions(prg, err)
 			return err
 		}
-		fmt.Fprint(prg.Stdout(), tr)
-		return nil
+		return printTree(prg, tr, cmd.format, popts...)
 	}
 }
@@ -0,0 +1,11 @@
+// +build !windows
+
+package main
+
+import "os"
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,29 @@
+// +build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// isTerminal reports whether f is a console able to print ANSI escape codes.
+// Consoles that don't process them by default are asked to do so.
+func isTerminal(f *os.File) bool {
+	var (
+		h    = syscall.Handle(f.Fd())
+		mode uint32
+	)
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	ok, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return ok != 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+)
+
+var errNoJournal = errors.New("no journal to undo, use -journal to set one")
+
+type undoCmd struct{}
+
+func (*undoCmd) register(getcfg func() appConfig) cli.ExecFunc {
+	return func(prg cli.Program) error {
+		appcfg := getcfg()
+		if appcfg.journal == "" {
+			return errNoJournal
+		}
+		drv := appcfg.fs
+		if jd, ok := drv.(fsutil.JournalDriver); ok {
+			drv = jd.Inner // undoing changes must not record them again
+		}
+		name := string(appcfg.journal)
+		b, err := ioutil.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		entries, err := fsutil.ReadJournal(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			err := appcfg.ctx.Err()
+			if err == nil {
+				err = entries[i].Undo(drv)
+			}
+			if err != nil {
+				if i < len(entries)-1 {
+					// Keep only what is left to undo, so it can be retried.
+					var buf bytes.Buffer
+					if werr := fsutil.WriteJournal(&buf, entries[:i+1]); werr != nil {
+						return werr
+					}
+					if werr := ioutil.WriteFile(name, buf.Bytes(), 0o644); werr != nil {
+						return werr
+					}
+				}
+				return err
+			}
+		}
+		return os.Remove(name)
+	}
+}
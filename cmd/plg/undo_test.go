@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/cli/clitest"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUndo(t *testing.T) {
+	testCases := []struct {
+		name string
+		// change is run between linking and undoing.
+		change  func(home string) error
+		entries int
+		err     error
+	}{
+		{
+			name:    "default",
+			change:  nil,
+			entries: 0,
+			err:     nil,
+		},
+		{
+			name: "link removed",
+			change: func(home string) error {
+				return os.Remove(filepath.Join(home, "config", "bar"))
+			},
+			entries: 0,
+			err:     nil,
+		},
+		{
+			name: "directory removed",
+			change: func(home string) error {
+				return os.RemoveAll(filepath.Join(home, "config"))
+			},
+			entries: 0,
+			err:     nil,
+		},
+		{
+			name: "changed",
+			change: func(home string) error {
+				link := filepath.Join(home, "config", "bar")
+				if err := os.Remove(link); err != nil {
+					return err
+				}
+				return ioutil.WriteFile(link, []byte("bar"), 0o644)
+			},
+			entries: 2,
+			err:     fsutil.ErrChanged,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "pilgo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			var (
+				home     = filepath.Join(dir, "home")
+				dotfiles = filepath.Join(home, "dotfiles")
+				journal  = filepath.Join(dir, "journal")
+				files    = map[string][]byte{
+					"foo":       []byte("foo"),
+					"bar":       []byte("bar"),
+					"pilgo.yml": yamlData(config.Config{Targets: []string{"bar", "foo"}}),
+				}
+			)
+			if err := os.MkdirAll(dotfiles, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, data := range files {
+				if err := ioutil.WriteFile(filepath.Join(dotfiles, name), data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			appcfg := appConfig{
+				conf:          "pilgo.yml",
+				fs:            fsutil.OSDriver{},
+				getwd:         func() (string, error) { return dotfiles, nil },
+				userConfigDir: func() (string, error) { return filepath.Join(home, "config"), nil },
+				userHomeDir:   func() (string, error) { return home, nil },
+				journal:       absPath(journal),
+			}
+			// The OS driver reads files relative to the real current directory.
+			cwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(dotfiles); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(cwd)
+			if err := new(linkCmd).register(appcfg.copy)(clitest.NewProgram("link")); err != nil {
+				t.Fatal(err)
+			}
+			if tc.change != nil {
+				if err := tc.change(home); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var (
+				exec = new(undoCmd).register(appcfg.copy)
+				prg  = clitest.NewProgram("undo")
+			)
+			err = exec(prg)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := "", prg.CombinedOutput(); got != want {
+				t.Fatalf("\"undo\" command output mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+			if tc.err != nil {
+				f, err := os.Open(journal)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				entries, err := fsutil.ReadJournal(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want, got := tc.entries, len(entries); got != want {
+					t.Fatalf("want %d entries left, got %d", want, got)
+				}
+				return
+			}
+			if _, err := os.Stat(journal); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+			}
+			infos, err := ioutil.ReadDir(home)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, fi := range infos {
+				names = append(names, fi.Name())
+			}
+			if want, got := []string{"dotfiles"}, names; !cmp.Equal(got, want) {
+				t.Fatalf("\"undo\" command mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+	t.Run("no journal", func(t *testing.T) {
+		appcfg := appConfig{fs: new(fstest.InMemoryDriver)}
+		err := new(undoCmd).register(appcfg.copy)(clitest.NewProgram("undo"))
+		if want, got := errNoJournal, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
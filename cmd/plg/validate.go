@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+type validateCmd struct{}
+
+func (*validateCmd) register(getcfg func() appConfig) cli.ExecFunc {
+	return func(prg cli.Program) error {
+		appcfg := getcfg()
+		fs := fs.New(fs.ReadOnly(appcfg.fs))
+		_, err := loadConfig(prg, fs, appcfg.conf)
+		return err
+	}
+}
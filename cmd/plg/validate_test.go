@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/cli/clitest"
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		drv     fstest.InMemoryDriver
+		wantErr string
+		err     error
+	}{
+		{
+			name: "default",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"default.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: yamlData(config.Config{
+											Targets: []string{
+												"foo",
+												"bar",
+											},
+											Options: map[string]*config.Config{
+												"foo": {Link: "f00"},
+											},
+										}),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: "",
+			err:     nil,
+		},
+		{
+			name: "problems",
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "home/dotfiles",
+				Files: map[string]fstest.File{
+					"home": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"dotfiles": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{
+									"problems.yml": {
+										Linkname: "",
+										Perm:     os.ModePerm,
+										Data: []byte(`usehome: true
+targets:
+- foo
+- foo
+options:
+  bar:
+    link: b/ar
+`),
+										Children: nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: `validate: problems.yml:1:1: unknown field "usehome"
+validate: problems.yml:4:3: duplicate target "foo"
+validate: problems.yml:6:3: options for unlisted target "bar"
+validate: problems.yml:7:11: invalid link name "b/ar": must not contain path separators
+`,
+			err: config.ErrUnknownField,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				orig   = tc.drv
+				appcfg = appConfig{
+					conf: filepath.Base(t.Name()) + ".yml",
+					fs:   &tc.drv,
+				}
+				exec = new(validateCmd).register(appcfg.copy)
+				prg  = clitest.NewProgram("validate")
+				err  = exec(prg)
+			)
+			if tc.err == nil && err != nil {
+				t.Fatalf("want %v, got %v", tc.err, err)
+			}
+			var verr *config.ValidationError
+			if tc.err != nil && !errors.As(err, &verr) {
+				t.Fatalf("want %T, got %v", verr, err)
+			}
+			if tc.err != nil && !errors.Is(verr.Errs[0], tc.err) {
+				t.Fatalf("want %v, got %v", tc.err, verr.Errs[0])
+			}
+			if want, got := "", prg.Output(); got != want {
+				t.Fatalf("\"validate\" command output mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			if want, got := tc.wantErr, prg.ErrOutput(); got != want {
+				t.Fatalf("\"validate\" command error output mismatch (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+			if want, got := orig, tc.drv; !cmp.Equal(got, want) {
+				t.Fatalf("\"validate\" command has unintended effects in the file system: (-want +got):\n%s",
+					cmp.Diff(want, got))
+			}
+		})
+	}
+}
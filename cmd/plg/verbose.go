@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gbrlsnchs/cli"
+	"github.com/gbrlsnchs/pilgo/linker"
+	"github.com/gbrlsnchs/pilgo/parser"
+)
+
+// verboseObserver prints what a linker does, as it happens.
+type verboseObserver struct {
+	mu   sync.Mutex
+	w    io.Writer
+	name string
+}
+
+func (obs *verboseObserver) NodeResolved(n *parser.Node) {
+	obs.printf("resolved %s: %v", n.Target.FullPath(), n.Status)
+}
+
+// ConflictFound does nothing, since commands print conflicts by themselves.
+func (obs *verboseObserver) ConflictFound(n *parser.Node, err error) {}
+
+func (obs *verboseObserver) DirCreated(dirname string) {
+	obs.printf("created directory %s", dirname)
+}
+
+func (obs *verboseObserver) SymlinkCreated(oldname, newname string) {
+	obs.printf("created symlink %s -> %s", newname, oldname)
+}
+
+func (obs *verboseObserver) printf(format string, args ...interface{}) {
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	fmt.Fprintf(obs.w, "%s: %s\n", obs.name, fmt.Sprintf(format, args...))
+}
+
+// linkerOptions returns options for linkers according to appcfg.
+func linkerOptions(prg cli.Program, appcfg appConfig) []linker.Option {
+	opts := []linker.Option{linker.Jobs(appcfg.jobs)}
+	if appcfg.verbose {
+		opts = append(opts, linker.WithObserver(&verboseObserver{w: prg.Stderr(), name: prg.Name()}))
+	}
+	return opts
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 )
@@ -11,6 +13,31 @@ const (
 	sep         = string(filepath.Separator)
 )
 
+var (
+	// ErrTargetNotExist means a target is not listed in the configuration.
+	ErrTargetNotExist = errors.New("target doesn't exist")
+	// ErrTargetExist means a target is already listed in the configuration.
+	ErrTargetExist = errors.New("target already exists")
+	// ErrTargetRecursive means a target can't be moved inside itself.
+	ErrTargetRecursive = errors.New("target can't be moved inside itself")
+)
+
+// Names of built-in base directories. Any other base must be declared as a root.
+const (
+	// BaseConfig is the user configuration directory. This is the default base.
+	BaseConfig = "config"
+	// BaseHome is the home directory.
+	BaseHome = "home"
+	// BaseData is the XDG data directory.
+	BaseData = "data"
+	// BaseState is the XDG state directory.
+	BaseState = "state"
+	// BaseCache is the XDG cache directory.
+	BaseCache = "cache"
+	// BaseBin is the user's executables directory.
+	BaseBin = "bin"
+)
+
 // SetMode is a type for the mode used when setting a configuration.
 type SetMode int
 
@@ -24,12 +51,15 @@ const (
 // Config is a configuration format for Pilgo.
 type Config struct {
 	BaseDir string             `yaml:"baseDir,omitempty"`
+	Base    string             `yaml:"base,omitempty"`
 	Link    string             `yaml:"link,omitempty"`
 	Targets []string           `yaml:"targets,omitempty"`
 	Options map[string]*Config `yaml:"options,omitempty"`
 	Flatten bool               `yaml:"flatten,omitempty"`
 	UseHome *bool              `yaml:"useHome,omitempty"`
 	Tags    []string           `yaml:"tags,omitempty"`
+	Roots   map[string]string  `yaml:"roots,omitempty"`
+	Vars    map[string]string  `yaml:"vars,omitempty"`
 }
 
 // Set sets o to path. The path may be nested, but will be a no-op if the
@@ -75,20 +105,155 @@ func (c *Config) Set(path string, new *Config, m SetMode) {
 	}
 }
 
+// Remove removes the target in path and all of its options, including nested targets.
+// Parent options left empty after the removal are also removed.
+func (c *Config) Remove(path string) error {
+	configs, tg := c.lookup(path, false)
+	if configs == nil || !configs[len(configs)-1].removeTarget(tg) {
+		return errWithPath(path, ErrTargetNotExist)
+	}
+	prune(configs, strings.Split(path, sep))
+	return nil
+}
+
+// Rename renames the target in oldpath to newpath, moving its options along. Both paths
+// may be nested, which allows moving targets between parents, as long as the new parent
+// is already a target.
+func (c *Config) Rename(oldpath, newpath string) error {
+	if oldpath == newpath {
+		return nil
+	}
+	if strings.HasPrefix(newpath, oldpath+sep) {
+		return errWithPath(newpath, ErrTargetRecursive)
+	}
+	oldconfigs, oldtg := c.lookup(oldpath, false)
+	if oldconfigs == nil || !oldconfigs[len(oldconfigs)-1].hasTarget(oldtg) {
+		return errWithPath(oldpath, ErrTargetNotExist)
+	}
+	// Creating missing options for the new parent is harmless in case of conflict,
+	// since the new target can't exist under a parent without options.
+	newconfigs, newtg := c.lookup(newpath, true)
+	if newconfigs == nil {
+		return errWithPath(filepath.Dir(newpath), ErrTargetNotExist)
+	}
+	oldparent, newparent := oldconfigs[len(oldconfigs)-1], newconfigs[len(newconfigs)-1]
+	if newparent.hasTarget(newtg) {
+		return errWithPath(newpath, ErrTargetExist)
+	}
+	opts := oldparent.Options[oldtg]
+	if oldparent == newparent {
+		// Keep the original order when only renaming.
+		for i, t := range newparent.Targets {
+			if t == oldtg {
+				newparent.Targets[i] = newtg
+			}
+		}
+		delete(newparent.Options, oldtg)
+	} else {
+		oldparent.removeTarget(oldtg)
+		newparent.Targets = append(newparent.Targets, newtg)
+	}
+	if opts != nil {
+		if newparent.Options == nil {
+			newparent.Options = make(map[string]*Config, 1)
+		}
+		newparent.Options[newtg] = opts
+	}
+	prune(oldconfigs, strings.Split(oldpath, sep))
+	return nil
+}
+
+// lookup returns the chain of configurations that lead to the target in path, starting
+// from the root configuration, and the target's name. Every parent in path must be a
+// target, otherwise it returns a nil chain. When create is true, missing options for
+// parent targets are created.
+func (c *Config) lookup(path string, create bool) ([]*Config, string) {
+	if path == "" {
+		return nil, ""
+	}
+	targets := strings.Split(path, sep)
+	configs := make([]*Config, 0, len(targets))
+	configs = append(configs, c)
+	for _, tg := range targets[:len(targets)-1] {
+		if !c.hasTarget(tg) {
+			return nil, ""
+		}
+		next := c.Options[tg]
+		if next == nil {
+			if !create {
+				return nil, ""
+			}
+			if c.Options == nil {
+				c.Options = make(map[string]*Config, 1)
+			}
+			next = new(Config)
+			c.Options[tg] = next
+		}
+		c = next
+		configs = append(configs, c)
+	}
+	return configs, targets[len(targets)-1]
+}
+
+func (c *Config) hasTarget(tg string) bool {
+	for _, t := range c.Targets {
+		if t == tg {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) removeTarget(tg string) bool {
+	for i, t := range c.Targets {
+		if t == tg {
+			c.Targets = append(c.Targets[:i], c.Targets[i+1:]...)
+			if len(c.Targets) == 0 {
+				c.Targets = nil
+			}
+			delete(c.Options, tg)
+			if len(c.Options) == 0 {
+				c.Options = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes empty options from the bottom to the top of a configuration chain.
+func prune(configs []*Config, targets []string) {
+	for i := len(configs) - 1; i > 0; i-- {
+		if !configs[i].isEmpty() {
+			return
+		}
+		parent := configs[i-1]
+		delete(parent.Options, targets[i-1])
+		if len(parent.Options) == 0 {
+			parent.Options = nil
+		}
+	}
+}
+
 func (c *Config) isEmpty() bool {
 	return c.BaseDir == "" &&
+		c.Base == "" &&
 		c.Link == "" &&
 		len(c.Targets) == 0 &&
 		len(c.Options) == 0 &&
 		c.UseHome == nil &&
 		!c.Flatten &&
-		len(c.Tags) == 0
+		len(c.Tags) == 0 &&
+		len(c.Roots) == 0 &&
+		len(c.Vars) == 0
 }
 
 func (c *Config) resolveNew(new *Config, m SetMode) *Config {
 	switch m {
 	case ModeConfig:
 		new.Targets = c.Targets
+		new.Roots = c.Roots
+		new.Vars = c.Vars
 	case ModeScan:
 		tgs := new.Targets
 		*new = *c
@@ -99,3 +264,5 @@ func (c *Config) resolveNew(new *Config, m SetMode) *Config {
 	}
 	return new
 }
+
+func errWithPath(path string, err error) error { return fmt.Errorf("config: %s: %w", path, err) }
@@ -1,10 +1,12 @@
 package config_test
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 
 	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/internal"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
@@ -12,9 +14,231 @@ import (
 var ignoreUnexported = cmpopts.IgnoreUnexported(config.Config{})
 
 func TestConfig(t *testing.T) {
+	t.Run("Remove", testConfigRemove)
+	t.Run("Rename", testConfigRename)
 	t.Run("Set", testConfigSet)
 }
 
+func testConfigRemove(t *testing.T) {
+	testCases := []struct {
+		c    config.Config
+		name string
+		want config.Config
+		err  error
+	}{
+		{
+			c: config.Config{
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"foo": {Link: "baz"},
+				},
+			},
+			name: "foo",
+			want: config.Config{
+				Targets: []string{"bar"},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Targets: []string{"bar"},
+						Options: map[string]*config.Config{
+							"bar": {Flatten: true},
+						},
+					},
+				},
+			},
+			name: filepath.Join("foo", "bar"),
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Targets: []string{"bar", "baz"},
+						UseHome: internal.NewBool(true),
+					},
+				},
+			},
+			name: filepath.Join("foo", "bar"),
+			want: config.Config{
+				Targets: []string{"foo"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Targets: []string{"baz"},
+						UseHome: internal.NewBool(true),
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+			},
+			name: "bar",
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: config.ErrTargetNotExist,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+			},
+			name: filepath.Join("foo", "bar"),
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: config.ErrTargetNotExist,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			err := tc.c.Remove(tc.name)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, tc.c; !cmp.Equal(got, want, ignoreUnexported) {
+				t.Errorf("(*Config).Remove mismatch (-want +got):\n%s", cmp.Diff(want, got, ignoreUnexported))
+			}
+		})
+	}
+}
+
+func testConfigRename(t *testing.T) {
+	testCases := []struct {
+		c       config.Config
+		oldname string
+		newname string
+		want    config.Config
+		err     error
+	}{
+		{
+			c: config.Config{
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"foo": {Link: "baz"},
+				},
+			},
+			oldname: "foo",
+			newname: "qux",
+			want: config.Config{
+				Targets: []string{"qux", "bar"},
+				Options: map[string]*config.Config{
+					"qux": {Link: "baz"},
+				},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Targets: []string{"baz"},
+					},
+				},
+			},
+			oldname: filepath.Join("foo", "baz"),
+			newname: filepath.Join("bar", "baz"),
+			want: config.Config{
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"bar": {
+						Targets: []string{"baz"},
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Targets: []string{"bar"},
+						Options: map[string]*config.Config{
+							"bar": {Flatten: true},
+						},
+					},
+				},
+			},
+			oldname: filepath.Join("foo", "bar"),
+			newname: "bar",
+			want: config.Config{
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"bar": {Flatten: true},
+				},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo", "bar"},
+			},
+			oldname: "foo",
+			newname: "bar",
+			want: config.Config{
+				Targets: []string{"foo", "bar"},
+			},
+			err: config.ErrTargetExist,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+			},
+			oldname: "bar",
+			newname: "baz",
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: config.ErrTargetNotExist,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+			},
+			oldname: "foo",
+			newname: filepath.Join("bar", "foo"),
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: config.ErrTargetNotExist,
+		},
+		{
+			c: config.Config{
+				Targets: []string{"foo"},
+			},
+			oldname: "foo",
+			newname: filepath.Join("foo", "bar"),
+			want: config.Config{
+				Targets: []string{"foo"},
+			},
+			err: config.ErrTargetRecursive,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			err := tc.c.Rename(tc.oldname, tc.newname)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, tc.c; !cmp.Equal(got, want, ignoreUnexported) {
+				t.Errorf("(*Config).Rename mismatch (-want +got):\n%s", cmp.Diff(want, got, ignoreUnexported))
+			}
+		})
+	}
+}
+
 func testConfigSet(t *testing.T) {
 	testCases := []struct {
 		c    config.Config
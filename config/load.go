@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	boolTag = "!!bool"
+	nullTag = "!!null"
+)
+
+var (
+	// ErrUnknownField means a field doesn't exist in the configuration format.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrInvalidType means a field has a value of the wrong type.
+	ErrInvalidType = errors.New("invalid type")
+	// ErrDuplicateTarget means a target is listed more than once.
+	ErrDuplicateTarget = errors.New("duplicate target")
+	// ErrOptionNotTarget means there are options for a target that is not listed.
+	ErrOptionNotTarget = errors.New("options for unlisted target")
+	// ErrInvalidLink means a link name is not a single path segment.
+	ErrInvalidLink = errors.New("invalid link name")
+	// ErrUnknownBase means a base is neither built-in nor a declared root.
+	ErrUnknownBase = errors.New("unknown base")
+	// ErrNotTopLevel means a field is declared outside the top level of the configuration.
+	ErrNotTopLevel = errors.New("must be declared at the top level")
+	// ErrReservedRoot means a root has the same name as a built-in base.
+	ErrReservedRoot = errors.New("reserved root name")
+)
+
+// Load decodes and validates the configuration in b. If any problems are found,
+// it returns a *ValidationError containing all of them, each one being a *Problem.
+func Load(b []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	if len(doc.Content) == 0 {
+		return c, nil // empty file
+	}
+	var v validator
+	v.validate(doc.Content[0], true)
+	if len(v.errs) > 0 {
+		sort.SliceStable(v.errs, func(i, j int) bool {
+			pi, pj := v.errs[i].(*Problem), v.errs[j].(*Problem)
+			return pi.Line < pj.Line || pi.Line == pj.Line && pi.Column < pj.Column
+		})
+		return nil, &ValidationError{v.errs}
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type validator struct {
+	errs  []error
+	roots map[string]struct{}
+}
+
+func (v *validator) validate(n *yaml.Node, top bool) {
+	if !v.checkKind(n, "", yaml.MappingNode, "mapping") {
+		return
+	}
+	if top {
+		// Roots must be known before validating bases, wherever they're declared.
+		for i := 0; i < len(n.Content); i += 2 {
+			if key, value := n.Content[i], n.Content[i+1]; key.Value == "roots" {
+				v.validateRoots(value)
+			}
+		}
+	}
+	var (
+		targets map[string]struct{}
+		options *yaml.Node
+	)
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch key.Value {
+		case "baseDir":
+			v.checkKind(value, key.Value, yaml.ScalarNode, "string")
+		case "base":
+			if !v.checkKind(value, key.Value, yaml.ScalarNode, "string") {
+				continue
+			}
+			if _, ok := v.roots[value.Value]; !ok && !IsBuiltinBase(value.Value) {
+				v.report(value, fmt.Errorf("%w %q", ErrUnknownBase, value.Value))
+			}
+		case "roots":
+			if !top {
+				v.report(key, fmt.Errorf("%q %w", key.Value, ErrNotTopLevel))
+			}
+		case "vars":
+			if !top {
+				v.report(key, fmt.Errorf("%q %w", key.Value, ErrNotTopLevel))
+				continue
+			}
+			if !v.checkKind(value, key.Value, yaml.MappingNode, "mapping") {
+				continue
+			}
+			for i := 1; i < len(value.Content); i += 2 {
+				v.checkKind(value.Content[i], key.Value, yaml.ScalarNode, "string")
+			}
+		case "link":
+			if !v.checkKind(value, key.Value, yaml.ScalarNode, "string") {
+				continue
+			}
+			if strings.ContainsAny(value.Value, "/"+string(filepath.Separator)) {
+				v.report(value, fmt.Errorf("%w %q: must not contain path separators", ErrInvalidLink, value.Value))
+			}
+		case "targets":
+			targets = v.validateTargets(value)
+		case "options":
+			if v.checkKind(value, key.Value, yaml.MappingNode, "mapping") {
+				options = value
+			}
+		case "flatten", "useHome":
+			if v.checkKind(value, key.Value, yaml.ScalarNode, "boolean") &&
+				value.ShortTag() != boolTag {
+				v.report(value, fmt.Errorf("%w for %q: want boolean", ErrInvalidType, key.Value))
+			}
+		case "tags":
+			if !v.checkKind(value, key.Value, yaml.SequenceNode, "list") {
+				continue
+			}
+			for _, tag := range value.Content {
+				v.checkKind(tag, key.Value, yaml.ScalarNode, "string")
+			}
+		default:
+			v.report(key, fmt.Errorf("%w %q", ErrUnknownField, key.Value))
+		}
+	}
+	if options == nil {
+		return
+	}
+	for i := 0; i < len(options.Content); i += 2 {
+		key, value := options.Content[i], options.Content[i+1]
+		if _, ok := targets[key.Value]; !ok {
+			v.report(key, fmt.Errorf("%w %q", ErrOptionNotTarget, key.Value))
+		}
+		v.validate(value, false)
+	}
+}
+
+func (v *validator) validateRoots(n *yaml.Node) {
+	v.roots = make(map[string]struct{})
+	if !v.checkKind(n, "roots", yaml.MappingNode, "mapping") {
+		return
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if IsBuiltinBase(key.Value) {
+			v.report(key, fmt.Errorf("%w %q", ErrReservedRoot, key.Value))
+			continue
+		}
+		if v.checkKind(value, "roots", yaml.ScalarNode, "string") {
+			v.roots[key.Value] = struct{}{}
+		}
+	}
+}
+
+func (v *validator) validateTargets(n *yaml.Node) map[string]struct{} {
+	targets := make(map[string]struct{})
+	if !v.checkKind(n, "targets", yaml.SequenceNode, "list") {
+		return targets
+	}
+	for _, tg := range n.Content {
+		if !v.checkKind(tg, "targets", yaml.ScalarNode, "string") {
+			continue
+		}
+		if _, ok := targets[tg.Value]; ok {
+			v.report(tg, fmt.Errorf("%w %q", ErrDuplicateTarget, tg.Value))
+			continue
+		}
+		targets[tg.Value] = struct{}{}
+	}
+	return targets
+}
+
+// checkKind reports a problem if n is not of the expected kind. Null values are always accepted.
+func (v *validator) checkKind(n *yaml.Node, field string, kind yaml.Kind, want string) bool {
+	if n.Kind == kind {
+		return true
+	}
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == nullTag {
+		return false
+	}
+	if field == "" {
+		v.report(n, fmt.Errorf("%w: want %s", ErrInvalidType, want))
+	} else {
+		v.report(n, fmt.Errorf("%w for %q: want %s", ErrInvalidType, field, want))
+	}
+	return false
+}
+
+func (v *validator) report(n *yaml.Node, err error) {
+	v.errs = append(v.errs, &Problem{Line: n.Line, Column: n.Column, Err: err})
+}
+
+// IsBuiltinBase returns whether name is the name of a built-in base.
+func IsBuiltinBase(name string) bool {
+	switch name {
+	case BaseConfig, BaseHome, BaseData, BaseState, BaseCache, BaseBin:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/config"
+	"github.com/gbrlsnchs/pilgo/internal"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	type problem struct {
+		line, column int
+		err          error
+		msg          string
+	}
+	testCases := []struct {
+		name     string
+		data     string
+		want     *config.Config
+		problems []problem
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: &config.Config{},
+		},
+		{
+			name: "valid",
+			data: `baseDir: test
+targets:
+- foo
+- bar
+options:
+  foo:
+    link: f00
+    useHome: true
+    tags:
+    - test
+  bar:
+    flatten: true
+    targets:
+    - baz
+`,
+			want: &config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"foo": {
+						Link:    "f00",
+						UseHome: internal.NewBool(true),
+						Tags:    []string{"test"},
+					},
+					"bar": {
+						Flatten: true,
+						Targets: []string{"baz"},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown fields",
+			data: `usehome: true
+targets:
+- foo
+option:
+  foo:
+    link: bar
+`,
+			problems: []problem{
+				{1, 1, config.ErrUnknownField, `1:1: unknown field "usehome"`},
+				{4, 1, config.ErrUnknownField, `4:1: unknown field "option"`},
+			},
+		},
+		{
+			name: "invalid targets",
+			data: `targets:
+- foo
+- bar
+- foo
+options:
+  baz:
+    link: foo/bar
+  bar:
+    flatten: yes please
+`,
+			problems: []problem{
+				{4, 3, config.ErrDuplicateTarget, `4:3: duplicate target "foo"`},
+				{6, 3, config.ErrOptionNotTarget, `6:3: options for unlisted target "baz"`},
+				{7, 11, config.ErrInvalidLink, `7:11: invalid link name "foo/bar": must not contain path separators`},
+				{9, 14, config.ErrInvalidType, `9:14: invalid type for "flatten": want boolean`},
+			},
+		},
+		{
+			name: "invalid types",
+			data: `targets: foo
+tags:
+- [bar]
+options: []
+`,
+			problems: []problem{
+				{1, 10, config.ErrInvalidType, `1:10: invalid type for "targets": want list`},
+				{3, 3, config.ErrInvalidType, `3:3: invalid type for "tags": want string`},
+				{4, 10, config.ErrInvalidType, `4:10: invalid type for "options": want mapping`},
+			},
+		},
+		{
+			name: "bases",
+			data: `base: data
+targets:
+- foo
+- bar
+options:
+  foo:
+    base: scripts
+  bar:
+    useHome: true
+roots:
+  scripts: /opt/scripts
+vars:
+  host: box
+`,
+			want: &config.Config{
+				Base:    config.BaseData,
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"foo": {Base: "scripts"},
+					"bar": {UseHome: internal.NewBool(true)},
+				},
+				Roots: map[string]string{"scripts": "/opt/scripts"},
+				Vars:  map[string]string{"host": "box"},
+			},
+		},
+		{
+			name: "invalid bases",
+			data: `base: foo
+targets:
+- bar
+options:
+  bar:
+    base: [home]
+    roots:
+      baz: /baz
+roots:
+  home: /home
+  qux: [/qux]
+`,
+			problems: []problem{
+				{1, 7, config.ErrUnknownBase, `1:7: unknown base "foo"`},
+				{6, 11, config.ErrInvalidType, `6:11: invalid type for "base": want string`},
+				{7, 5, config.ErrNotTopLevel, `7:5: "roots" must be declared at the top level`},
+				{10, 3, config.ErrReservedRoot, `10:3: reserved root name "home"`},
+				{11, 8, config.ErrInvalidType, `11:8: invalid type for "roots": want string`},
+			},
+		},
+		{
+			name: "vars",
+			data: `vars:
+  foo: bar
+  baz: ${QUX:-qux}
+targets:
+- ${foo}
+options:
+  ${foo}:
+    vars:
+      foo: baz
+    link: ${baz}
+`,
+			problems: []problem{
+				{8, 5, config.ErrNotTopLevel, `8:5: "vars" must be declared at the top level`},
+			},
+		},
+		{
+			name: "invalid vars",
+			data: `vars:
+  foo: [bar]
+targets:
+- foo
+`,
+			problems: []problem{
+				{2, 8, config.ErrInvalidType, `2:8: invalid type for "vars": want string`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := config.Load([]byte(tc.data))
+			if len(tc.problems) == 0 {
+				if err != nil {
+					t.Fatalf("want %v, got %v", nil, err)
+				}
+				if want, got := tc.want, c; !cmp.Equal(got, want, ignoreUnexported) {
+					t.Fatalf("config.Load mismatch (-want +got):\n%s", cmp.Diff(want, got, ignoreUnexported))
+				}
+				return
+			}
+			var verr *config.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("want %T, got %v", verr, err)
+			}
+			if want, got := len(tc.problems), len(verr.Errs); got != want {
+				t.Fatalf("want %d problems, got %d: %v", want, got, verr.Errs)
+			}
+			for i, p := range tc.problems {
+				var got *config.Problem
+				if !errors.As(verr.Errs[i], &got) {
+					t.Fatalf("want %T, got %T", got, verr.Errs[i])
+				}
+				if got.Line != p.line || got.Column != p.column {
+					t.Errorf("want %d:%d, got %d:%d", p.line, p.column, got.Line, got.Column)
+				}
+				if !errors.Is(got, p.err) {
+					t.Errorf("want %v, got %v", p.err, got)
+				}
+				if want, got := p.msg, got.Error(); got != want {
+					t.Errorf("want %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
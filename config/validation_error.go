@@ -0,0 +1,35 @@
+package config
+
+import "fmt"
+
+// ValidationError represents a group of problems found in a configuration.
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	errlen := len(e.Errs)
+	verb, problems := resolveWords(errlen)
+	return fmt.Sprintf("config: there %s %d %s", verb, errlen, problems)
+}
+
+// Problem is an error found at a specific position of a configuration file.
+type Problem struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (p *Problem) Error() string { return fmt.Sprintf("%d:%d: %v", p.Line, p.Column, p.Err) }
+
+// Unwrap returns the underlying error.
+func (p *Problem) Unwrap() error { return p.Err }
+
+func resolveWords(n int) (string, string) {
+	switch {
+	case n == 1:
+		return "is", "problem"
+	default:
+		return "are", "problems"
+	}
+}
@@ -0,0 +1,41 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/config"
+)
+
+func TestValidationError(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		testCases := []struct {
+			verr *config.ValidationError
+			want string
+		}{
+			{new(config.ValidationError), "config: there are 0 problems"},
+			{&config.ValidationError{Errs: make([]error, 1)}, "config: there is 1 problem"},
+			{&config.ValidationError{Errs: make([]error, 2)}, "config: there are 2 problems"},
+		}
+		for _, tc := range testCases {
+			t.Run("", func(t *testing.T) {
+				errm := tc.verr.Error()
+				if want, got := tc.want, errm; got != want {
+					t.Fatalf("want %q, got %q", want, got)
+				}
+			})
+		}
+	})
+}
+
+func TestProblem(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		p := &config.Problem{Line: 3, Column: 5, Err: config.ErrUnknownField}
+		if want, got := "3:5: unknown field", p.Error(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		if want, got := config.ErrUnknownField, p; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
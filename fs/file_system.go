@@ -10,6 +10,7 @@ import (
 var ErrNoDriver = errors.New("fs: nil driver")
 
 // FileSystem is a concrete file system that implements a VFS contract.
+// It's safe for concurrent use as long as its driver is.
 type FileSystem struct {
 	drv Driver
 }
@@ -19,6 +20,8 @@ type Driver interface {
 	MkdirAll(dirname string) error
 	ReadDir(dirname string) ([]FileInfo, error)
 	ReadFile(filename string) ([]byte, error)
+	Remove(filename string) error
+	Rename(oldname, newname string) error
 	Stat(filename string) (FileInfo, error)
 	Symlink(oldname, newname string) error
 	WriteFile(filename string, data []byte, perm os.FileMode) error
@@ -50,6 +53,21 @@ func (fs FileSystem) ReadFile(filename string) ([]byte, error) {
 	return fs.drv.ReadFile(filename)
 }
 
+// Remove removes a file or an empty directory.
+func (fs FileSystem) Remove(filename string) error {
+	fs.testDriver()
+	filename = filepath.FromSlash(filename)
+	return fs.drv.Remove(filename)
+}
+
+// Rename moves oldname to newname.
+func (fs FileSystem) Rename(oldname, newname string) error {
+	fs.testDriver()
+	oldname = filepath.FromSlash(oldname)
+	newname = filepath.FromSlash(newname)
+	return fs.drv.Rename(oldname, newname)
+}
+
 // Stat returns information about a file.
 func (fs FileSystem) Stat(filename string) (FileInfo, error) {
 	fs.testDriver()
@@ -15,6 +15,8 @@ func TestFileSystem(t *testing.T) {
 	t.Run("MkdirAll", testFileSystemMkdirAll)
 	t.Run("ReadDir", testFileSystemReadDir)
 	t.Run("ReadFile", testFileSystemReadFile)
+	t.Run("Remove", testFileSystemRemove)
+	t.Run("Rename", testFileSystemRename)
 	t.Run("Stat", testFileSystemStat)
 	t.Run("WriteFile", testFileSystemWriteFile)
 }
@@ -97,6 +99,60 @@ func testFileSystemReadFile(t *testing.T) {
 	}
 }
 
+func testFileSystemRemove(t *testing.T) {
+	testCases := []struct {
+		drv fs.Driver
+		err error
+	}{
+		{nil, fs.ErrNoDriver},
+		{new(fstest.SpyDriver), nil},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			defer checkPanic(t, tc.err)
+			fs := fs.New(tc.drv)
+			_ = fs.Remove("test/foo")
+			drv := tc.drv.(*fstest.SpyDriver)
+			hasBeenCalled, args := drv.HasBeenCalled(drv.Remove)
+			if want, got := true, hasBeenCalled; got != want {
+				t.Fatalf("want %t, got %t", want, got)
+			}
+			callstack := fstest.CallStack{fstest.Args{filepath.Join("test", "foo")}}
+			if want, got := callstack, args; !cmp.Equal(got, want) {
+				t.Fatalf("FileSystem.Remove mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testFileSystemRename(t *testing.T) {
+	testCases := []struct {
+		drv fs.Driver
+		err error
+	}{
+		{nil, fs.ErrNoDriver},
+		{new(fstest.SpyDriver), nil},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			defer checkPanic(t, tc.err)
+			fs := fs.New(tc.drv)
+			_ = fs.Rename("test/foo", "test/bar")
+			drv := tc.drv.(*fstest.SpyDriver)
+			hasBeenCalled, args := drv.HasBeenCalled(drv.Rename)
+			if want, got := true, hasBeenCalled; got != want {
+				t.Fatalf("want %t, got %t", want, got)
+			}
+			callstack := fstest.CallStack{
+				fstest.Args{filepath.Join("test", "foo"), filepath.Join("test", "bar")},
+			}
+			if want, got := callstack, args; !cmp.Equal(got, want) {
+				t.Fatalf("FileSystem.Rename mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
 func testFileSystemStat(t *testing.T) {
 	testCases := []struct {
 		drv fs.Driver
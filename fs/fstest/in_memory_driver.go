@@ -27,6 +27,10 @@ var (
 	ErrExist = errors.New("file already exist")
 	// ErrNotDir means a file is not a directory.
 	ErrNotDir = errors.New("file is not a directory")
+	// ErrNotEmpty means a directory has files inside it.
+	ErrNotEmpty = errors.New("directory is not empty")
+	// ErrSubdir means a directory can't be moved into one of its own subdirectories.
+	ErrSubdir = errors.New("directory can't be moved into itself")
 
 	pathSep = string(filepath.Separator)
 )
@@ -65,15 +69,67 @@ func (drv *InMemoryDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
 	return list, nil
 }
 
-// ReadFile simulates a file read. It returns data associated with a file or an error
-// if the file can't be found.
+// ReadFile simulates a file read. It returns a copy of the data associated with a file
+// or an error if the file can't be found.
 func (drv *InMemoryDriver) ReadFile(filename string) ([]byte, error) {
 	filename = drv.resolvePath(filename)
 	fstat, err := drv.find(filename)
 	if err != nil {
 		return nil, err
 	}
-	return fstat.File.Data, nil
+	// Return a copy, just like reading a real file does.
+	return append([]byte(nil), fstat.File.Data...), nil
+}
+
+// Remove simulates removing a file or an empty directory. It returns an error
+// if filename doesn't exist or is a directory with files inside it.
+func (drv *InMemoryDriver) Remove(filename string) error {
+	filename = drv.resolvePath(filename)
+	fstat, err := drv.find(filename)
+	if err != nil {
+		return err
+	}
+	if len(fstat.File.Children) > 0 {
+		return ErrNotEmpty
+	}
+	parent, err := drv.parent(filename)
+	if err != nil {
+		return err
+	}
+	delete(parent, fstat.Label)
+	return nil
+}
+
+// Rename simulates moving oldname to newname. Just like in a real file system,
+// newname gets replaced if it exists and is not a directory.
+func (drv *InMemoryDriver) Rename(oldname, newname string) error {
+	oldname = drv.resolvePath(oldname)
+	newname = drv.resolvePath(newname)
+	fstat, err := drv.find(oldname)
+	if err != nil {
+		return err
+	}
+	if fstat.IsDir() && strings.HasPrefix(newname, oldname+pathSep) {
+		return ErrSubdir
+	}
+	oldparent, err := drv.parent(oldname)
+	if err != nil {
+		return err
+	}
+	target, err := drv.find(newname)
+	if err != nil && !errors.Is(err, ErrNotExist) {
+		return err
+	}
+	if target.IsDir() {
+		return ErrExist
+	}
+	newparent, err := drv.parent(newname)
+	if err != nil {
+		return err
+	}
+	delete(oldparent, fstat.Label)
+	newparent[filepath.Base(newname)] = fstat.File
+	return nil
 }
 
 // Stat simulates reading information about filename. If filename doesn't exist, instead of
@@ -147,6 +203,21 @@ func (drv *InMemoryDriver) create(filename string, f File, opts createOpts) erro
 	return nil
 }
 
+func (drv *InMemoryDriver) parent(filename string) (map[string]File, error) {
+	dir := filepath.Dir(filename)
+	if dir == "." {
+		return drv.Files, nil
+	}
+	fstat, err := drv.find(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fstat.IsDir() {
+		return nil, ErrNotDir
+	}
+	return fstat.File.Children, nil
+}
+
 func (drv *InMemoryDriver) find(filename string) (FileStat, error) {
 	var (
 		fstat FileStat
@@ -26,6 +26,8 @@ func TestInMemoryDriver(t *testing.T) {
 	t.Run("MkdirAll", testInMemoryDriverMkdirAll)
 	t.Run("ReadDir", testInMemoryDriverReadDir)
 	t.Run("ReadFile", testInMemoryDriverReadFile)
+	t.Run("Remove", testInMemoryDriverRemove)
+	t.Run("Rename", testInMemoryDriverRename)
 	t.Run("Stat", testInMemoryDriverStat)
 	t.Run("Symlink", testInMemoryDriverSymlink)
 	t.Run("WriteFile", testInMemoryDriverWriteFile)
@@ -512,6 +514,358 @@ func testInMemoryDriverReadFile(t *testing.T) {
 	}
 }
 
+func testInMemoryDriverRemove(t *testing.T) {
+	testCases := []struct {
+		drv      fstest.InMemoryDriver
+		filename string
+		want     fstest.InMemoryDriver
+		err      error
+	}{
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			filename: "foo",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{},
+			},
+			err: nil,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			filename: "bar",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			err: fstest.ErrNotExist,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     []byte("test"),
+								Children: nil,
+							},
+						},
+					},
+				},
+			},
+			filename: "foo",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     []byte("test"),
+								Children: nil,
+							},
+						},
+					},
+				},
+			},
+			err: fstest.ErrNotEmpty,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "foo",
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "baz",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: nil,
+							},
+						},
+					},
+				},
+			},
+			filename: "bar",
+			want: fstest.InMemoryDriver{
+				CurrentDir: "foo",
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{},
+					},
+				},
+			},
+			err: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			err := tc.drv.Remove(tc.filename)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, tc.drv; !cmp.Equal(got, want) {
+				t.Fatalf("(-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testInMemoryDriverRename(t *testing.T) {
+	testCases := []struct {
+		drv     fstest.InMemoryDriver
+		oldname string
+		newname string
+		want    fstest.InMemoryDriver
+		err     error
+	}{
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			oldname: "foo",
+			newname: "bar",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"bar": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test_foo"),
+						Children: nil,
+					},
+					"bar": {
+						Linkname: "",
+						Perm:     0o644,
+						Data:     []byte("test_bar"),
+						Children: nil,
+					},
+				},
+			},
+			oldname: "foo",
+			newname: "bar",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"bar": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test_foo"),
+						Children: nil,
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+					"bar": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: make(map[string]fstest.File),
+					},
+				},
+			},
+			oldname: "foo",
+			newname: "bar",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+					"bar": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: make(map[string]fstest.File),
+					},
+				},
+			},
+			err: fstest.ErrExist,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			oldname: "bar",
+			newname: "baz",
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			err: fstest.ErrNotExist,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				CurrentDir: "foo",
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     []byte("test"),
+								Children: nil,
+							},
+						},
+					},
+				},
+			},
+			oldname: "bar",
+			newname: fstest.AbsPath("baz"),
+			want: fstest.InMemoryDriver{
+				CurrentDir: "foo",
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{},
+					},
+					"baz": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     []byte("test"),
+						Children: nil,
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			drv: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{},
+							},
+						},
+					},
+				},
+			},
+			oldname: "foo",
+			newname: filepath.Join("foo", "bar", "foo"),
+			want: fstest.InMemoryDriver{
+				Files: map[string]fstest.File{
+					"foo": {
+						Linkname: "",
+						Perm:     os.ModePerm,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"bar": {
+								Linkname: "",
+								Perm:     os.ModePerm,
+								Data:     nil,
+								Children: map[string]fstest.File{},
+							},
+						},
+					},
+				},
+			},
+			err: fstest.ErrSubdir,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.oldname+" "+tc.newname, func(t *testing.T) {
+			err := tc.drv.Rename(tc.oldname, tc.newname)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, tc.drv; !cmp.Equal(got, want) {
+				t.Fatalf("(-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
 func testInMemoryDriverStat(t *testing.T) {
 	testCases := []struct {
 		drv      fstest.InMemoryDriver
@@ -19,6 +19,10 @@ type SpyDriver struct {
 	// ReadFile
 	ReadFileReturn map[string][]byte
 	ReadFileErr    map[string]error
+	// Remove
+	RemoveErr map[string]error
+	// Rename
+	RenameErr map[string]error
 
 	// Stat
 	StatReturn map[string]fs.FileInfo
@@ -60,6 +64,18 @@ func (drv *SpyDriver) ReadFile(filename string) ([]byte, error) {
 	return drv.ReadFileReturn[filename], drv.ReadFileErr[filename]
 }
 
+// Remove returns a stub of a file removal.
+func (drv *SpyDriver) Remove(filename string) error {
+	defer drv.setHasBeenCalled(drv.Remove, filename)
+	return drv.RemoveErr[filename]
+}
+
+// Rename returns a stub of a file renaming.
+func (drv *SpyDriver) Rename(oldname, newname string) error {
+	defer drv.setHasBeenCalled(drv.Rename, oldname, newname)
+	return drv.RenameErr[oldname]
+}
+
 func (drv *SpyDriver) Stat(filename string) (fs.FileInfo, error) {
 	defer drv.setHasBeenCalled(drv.Stat, filename)
 	return drv.StatReturn[filename], drv.StatErr[filename]
@@ -16,6 +16,8 @@ func TestSpyDriver(t *testing.T) {
 	t.Run("MkdirAll", testSpyDriverMkdirAll)
 	t.Run("ReadDir", testSpyDriverReadDir)
 	t.Run("ReadFile", testSpyDriverReadFile)
+	t.Run("Remove", testSpyDriverRemove)
+	t.Run("Rename", testSpyDriverRename)
 	t.Run("Stat", testSpyDriverStat)
 	t.Run("Symlink", testSpyDriverSymlink)
 	t.Run("WriteFile", testSpyDriverWriteFile)
@@ -343,6 +345,78 @@ func testSpyDriverStat(t *testing.T) {
 	}
 }
 
+func testSpyDriverRemove(t *testing.T) {
+	errRemove := errors.New("Remove")
+	testCases := []struct {
+		drv      fstest.SpyDriver
+		filename string
+		err      error
+	}{
+		{
+			drv: fstest.SpyDriver{
+				RemoveErr: map[string]error{
+					"foo": errRemove,
+				},
+			},
+			filename: "foo",
+			err:      errRemove,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			err := tc.drv.Remove(tc.filename)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			hasBeenCalled, args := tc.drv.HasBeenCalled(tc.drv.Remove)
+			if want, got := true, hasBeenCalled; got != want {
+				t.Fatalf("want %t, got %t", want, got)
+			}
+			callstack := fstest.CallStack{fstest.Args{tc.filename}}
+			if want, got := callstack, args; !cmp.Equal(got, want) {
+				t.Fatalf("(-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testSpyDriverRename(t *testing.T) {
+	errRename := errors.New("Rename")
+	testCases := []struct {
+		drv     fstest.SpyDriver
+		oldname string
+		newname string
+		err     error
+	}{
+		{
+			drv: fstest.SpyDriver{
+				RenameErr: map[string]error{
+					"foo": errRename,
+				},
+			},
+			oldname: "foo",
+			newname: "bar",
+			err:     errRename,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.oldname+" "+tc.newname, func(t *testing.T) {
+			err := tc.drv.Rename(tc.oldname, tc.newname)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			hasBeenCalled, args := tc.drv.HasBeenCalled(tc.drv.Rename)
+			if want, got := true, hasBeenCalled; got != want {
+				t.Fatalf("want %t, got %t", want, got)
+			}
+			callstack := fstest.CallStack{fstest.Args{tc.oldname, tc.newname}}
+			if want, got := callstack, args; !cmp.Equal(got, want) {
+				t.Fatalf("(-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
 func testSpyDriverSymlink(t *testing.T) {
 	errSymlink := errors.New("Symlink")
 	testCases := []struct {
@@ -0,0 +1,125 @@
+// +build go1.16
+
+package fsutil
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+// IOFSDriver is a read-only driver for an io/fs file system. Every path is relative to
+// the root of FS, even absolute ones, and paths can't escape it. Symlinks are reported
+// as such only when FS can read them, as described by io/fs.ReadLinkFS in Go 1.25.
+type IOFSDriver struct {
+	FS iofs.FS
+}
+
+// readLinkFS is the same as io/fs.ReadLinkFS, which was only introduced in Go 1.25.
+type readLinkFS interface {
+	iofs.FS
+	ReadLink(name string) (string, error)
+	Lstat(name string) (iofs.FileInfo, error)
+}
+
+// MkdirAll always fails, since the driver is read-only.
+func (IOFSDriver) MkdirAll(dirname string) error {
+	return &iofs.PathError{Op: "mkdir", Path: dirname, Err: iofs.ErrPermission}
+}
+
+// ReadDir lists names of files from dirname.
+func (drv IOFSDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
+	dirname = fsPath(dirname)
+	entries, err := iofs.ReadDir(drv.FS, dirname)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]fs.FileInfo, len(entries))
+	for i, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		if names[i], err = drv.fileInfo(path.Join(dirname, e.Name()), fi); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// ReadFile returns the content of filename.
+func (drv IOFSDriver) ReadFile(filename string) ([]byte, error) {
+	return iofs.ReadFile(drv.FS, fsPath(filename))
+}
+
+// Remove always fails, since the driver is read-only.
+func (IOFSDriver) Remove(filename string) error {
+	return &iofs.PathError{Op: "remove", Path: filename, Err: iofs.ErrPermission}
+}
+
+// Rename always fails, since the driver is read-only.
+func (IOFSDriver) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: iofs.ErrPermission}
+}
+
+// Stat returns information about a file without following symlinks, if FS can read them.
+func (drv IOFSDriver) Stat(filename string) (fs.FileInfo, error) {
+	filename = fsPath(filename)
+	var (
+		fi  iofs.FileInfo
+		err error
+	)
+	if rl, ok := drv.FS.(readLinkFS); ok {
+		fi, err = rl.Lstat(filename)
+	} else {
+		fi, err = iofs.Stat(drv.FS, filename)
+	}
+	if err != nil {
+		if !errors.Is(err, iofs.ErrNotExist) {
+			return nil, err
+		}
+		return fileInfo{exists: false}, nil
+	}
+	return drv.fileInfo(filename, fi)
+}
+
+// Symlink always fails, since the driver is read-only.
+func (IOFSDriver) Symlink(oldname, newname string) error {
+	return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: iofs.ErrPermission}
+}
+
+// WriteFile always fails, since the driver is read-only.
+func (IOFSDriver) WriteFile(filename string, _ []byte, _ os.FileMode) error {
+	return &iofs.PathError{Op: "open", Path: filename, Err: iofs.ErrPermission}
+}
+
+func (drv IOFSDriver) fileInfo(name string, fi iofs.FileInfo) (fs.FileInfo, error) {
+	mode := fi.Mode()
+	info := fileInfo{
+		name:   fi.Name(),
+		exists: true,
+		isDir:  fi.IsDir(),
+		perm:   mode.Perm(),
+	}
+	if rl, ok := drv.FS.(readLinkFS); ok && mode&iofs.ModeSymlink != 0 {
+		var err error
+		if info.linkname, err = rl.ReadLink(name); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// fsPath converts name to a valid io/fs path. Absolute paths are made relative to
+// the root and, just like at the root of a real file system, ".." is ignored there.
+func fsPath(name string) string {
+	name = filepath.ToSlash(name[len(filepath.VolumeName(name)):])
+	if name = path.Clean("/" + name)[1:]; name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+// +build go1.16
+
+package fsutil_test
+
+import (
+	"errors"
+	iofs "io/fs"
+	"testing"
+	iofstest "testing/fstest"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+type readLinkFS interface {
+	ReadLink(string) (string, error)
+}
+
+func TestIOFSDriver(t *testing.T) {
+	mapfs := iofstest.MapFS{
+		"dotfiles/foo":     {Data: []byte("foo"), Mode: 0o644},
+		"dotfiles/bar/baz": {Data: []byte("baz"), Mode: 0o600},
+		"dotfiles/link":    {Data: []byte("foo"), Mode: iofs.ModeSymlink | 0o777},
+	}
+	drv := fsutil.IOFSDriver{FS: mapfs}
+	_, canReadLink := iofs.FS(mapfs).(readLinkFS)
+
+	t.Run("ReadDir", func(t *testing.T) {
+		files, err := drv.ReadDir("/dotfiles")
+		if err != nil {
+			t.Fatal(err)
+		}
+		type info struct {
+			Name     string
+			IsDir    bool
+			Linkname string
+		}
+		want := []info{{"bar", true, ""}, {"foo", false, ""}, {"link", false, ""}}
+		if canReadLink {
+			want[2].Linkname = "foo"
+		}
+		got := make([]info, len(files))
+		for i, fi := range files {
+			got[i] = info{fi.Name(), fi.IsDir(), fi.Linkname()}
+		}
+		if !cmp.Equal(got, want) {
+			t.Fatalf("IOFSDriver.ReadDir mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+	})
+	t.Run("ReadFile", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			want []byte
+		}{
+			{"dotfiles/foo", []byte("foo")},
+			{"/dotfiles/bar/baz", []byte("baz")},
+			{"/../dotfiles/foo", []byte("foo")},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				data, err := drv.ReadFile(tc.name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want, got := string(tc.want), string(data); got != want {
+					t.Fatalf("want %q, got %q", want, got)
+				}
+			})
+		}
+	})
+	t.Run("Stat", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			exists   bool
+			isDir    bool
+			linkname string
+			perm     iofs.FileMode
+		}{
+			{"/", true, true, "", 0o555},
+			{"/dotfiles/foo", true, false, "", 0o644},
+			{"/dotfiles/bar", true, true, "", 0o555},
+			{"/dotfiles/missing", false, false, "", 0},
+		}
+		if canReadLink {
+			testCases = append(testCases, struct {
+				name     string
+				exists   bool
+				isDir    bool
+				linkname string
+				perm     iofs.FileMode
+			}{"/dotfiles/link", true, false, "foo", 0o777})
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				fi, err := drv.Stat(tc.name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want, got := tc.exists, fi.Exists(); got != want {
+					t.Fatalf("want %t, got %t", want, got)
+				}
+				if !tc.exists {
+					return
+				}
+				if want, got := tc.isDir, fi.IsDir(); got != want {
+					t.Errorf("want %t, got %t", want, got)
+				}
+				if want, got := tc.linkname, fi.Linkname(); got != want {
+					t.Errorf("want %q, got %q", want, got)
+				}
+				if want, got := tc.perm, fi.Perm(); got != want {
+					t.Errorf("want %v, got %v", want, got)
+				}
+			})
+		}
+	})
+	t.Run("read-only", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			fn   func(fs.FileSystem) error
+		}{
+			{"MkdirAll", func(fs fs.FileSystem) error { return fs.MkdirAll("dotfiles/new") }},
+			{"Remove", func(fs fs.FileSystem) error { return fs.Remove("dotfiles/foo") }},
+			{"Rename", func(fs fs.FileSystem) error { return fs.Rename("dotfiles/foo", "dotfiles/new") }},
+			{"Symlink", func(fs fs.FileSystem) error { return fs.Symlink("dotfiles/foo", "dotfiles/new") }},
+			{"WriteFile", func(fs fs.FileSystem) error { return fs.WriteFile("dotfiles/new", nil, 0o644) }},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.fn(fs.New(drv))
+				if want, got := iofs.ErrPermission, err; !errors.Is(got, want) {
+					t.Fatalf("want %v, got %v", want, got)
+				}
+			})
+		}
+		if want, got := 3, len(mapfs); got != want {
+			t.Fatalf("want %d files, got %d", want, got)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package fsutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+// ErrChanged means a file has changed since its change was recorded in a journal,
+// so the change can't be safely undone.
+var ErrChanged = errors.New("file has changed since it was recorded")
+
+// Journal operations.
+const (
+	OpMkdirAll  = "MkdirAll"
+	OpRemove    = "Remove"
+	OpRename    = "Rename"
+	OpSymlink   = "Symlink"
+	OpWriteFile = "WriteFile"
+)
+
+// JournalDriver wraps Inner and records every call that modifies files, along
+// with its result, in Journal. Each call is written as a single JSON line, so
+// Journal can be a file opened for appending.
+//
+// Besides arguments, entries also record what is needed to undo them, like which
+// directories were created or what was in a file before it was overwritten.
+// Relative paths are recorded relative to Dir, if it's set.
+type JournalDriver struct {
+	Inner   fs.Driver
+	Journal io.Writer
+	Dir     string
+}
+
+// JournalEntry is a call recorded by JournalDriver.
+type JournalEntry struct {
+	Time  time.Time   `json:"time"`
+	Op    string      `json:"op"`
+	Args  []string    `json:"args"`
+	Data  []byte      `json:"data,omitempty"`
+	Perm  os.FileMode `json:"perm,omitempty"`
+	Error string      `json:"error,omitempty"`
+	// Dirs are the directories created by MkdirAll, parents first.
+	Dirs []string `json:"dirs,omitempty"`
+	// Prev is the file that was removed or replaced, if any.
+	Prev *JournalFile `json:"prev,omitempty"`
+}
+
+// JournalFile is a file recorded in a journal entry.
+type JournalFile struct {
+	Data     []byte      `json:"data,omitempty"`
+	Perm     os.FileMode `json:"perm"`
+	Linkname string      `json:"linkname,omitempty"`
+	IsDir    bool        `json:"dir,omitempty"`
+}
+
+// MkdirAll creates directories and records which of them didn't exist.
+func (drv JournalDriver) MkdirAll(dirname string) error {
+	var dirs []string
+	for dir := drv.abs(dirname); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		fi, err := drv.Inner.Stat(dir)
+		if err != nil || fi.Exists() {
+			break
+		}
+		dirs = append([]string{dir}, dirs...)
+	}
+	err := drv.Inner.MkdirAll(dirname)
+	return drv.record(JournalEntry{
+		Op:   OpMkdirAll,
+		Args: []string{drv.abs(dirname)},
+		Dirs: dirs,
+	}, err)
+}
+
+// ReadDir lists files without recording anything.
+func (drv JournalDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
+	return drv.Inner.ReadDir(dirname)
+}
+
+// ReadFile reads a file without recording anything.
+func (drv JournalDriver) ReadFile(filename string) ([]byte, error) {
+	return drv.Inner.ReadFile(filename)
+}
+
+// Remove removes a file and records it.
+func (drv JournalDriver) Remove(filename string) error {
+	prev, err := drv.file(filename)
+	if err != nil {
+		return err
+	}
+	err = drv.Inner.Remove(filename)
+	return drv.record(JournalEntry{
+		Op:   OpRemove,
+		Args: []string{drv.abs(filename)},
+		Prev: prev,
+	}, err)
+}
+
+// Rename moves a file and records the file it replaces, if any.
+func (drv JournalDriver) Rename(oldname, newname string) error {
+	prev, err := drv.file(newname)
+	if err != nil {
+		return err
+	}
+	err = drv.Inner.Rename(oldname, newname)
+	return drv.record(JournalEntry{
+		Op:   OpRename,
+		Args: []string{drv.abs(oldname), drv.abs(newname)},
+		Prev: prev,
+	}, err)
+}
+
+// Stat returns information about a file without recording anything.
+func (drv JournalDriver) Stat(filename string) (fs.FileInfo, error) {
+	return drv.Inner.Stat(filename)
+}
+
+// Symlink creates a symlink and records it.
+func (drv JournalDriver) Symlink(oldname, newname string) error {
+	err := drv.Inner.Symlink(oldname, newname)
+	return drv.record(JournalEntry{
+		Op:   OpSymlink,
+		Args: []string{oldname, drv.abs(newname)},
+	}, err)
+}
+
+// WriteFile writes a file and records the file it replaces, if any.
+func (drv JournalDriver) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	prev, err := drv.file(filename)
+	if err != nil {
+		return err
+	}
+	err = drv.Inner.WriteFile(filename, data, perm)
+	return drv.record(JournalEntry{
+		Op:   OpWriteFile,
+		Args: []string{drv.abs(filename)},
+		Data: data,
+		Perm: perm,
+		Prev: prev,
+	}, err)
+}
+
+// file returns the current state of name, or nil if it doesn't exist.
+func (drv JournalDriver) file(name string) (*JournalFile, error) {
+	return journalFile(drv.Inner, name)
+}
+
+func (drv JournalDriver) record(e JournalEntry, err error) error {
+	e.Time = time.Now()
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if werr := WriteJournal(drv.Journal, []JournalEntry{e}); err == nil {
+		err = werr
+	}
+	return err
+}
+
+func (drv JournalDriver) abs(name string) string {
+	if drv.Dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(drv.Dir, name)
+}
+
+// Undo reverts the change recorded in e. Entries that recorded an error are
+// ignored, and directories are only removed if they're empty. Symlinks and directories
+// that no longer exist are considered already undone. If a file has changed since the
+// entry was recorded, Undo returns ErrChanged without touching it.
+func (e JournalEntry) Undo(drv fs.Driver) error {
+	if e.Error != "" {
+		return nil
+	}
+	switch e.Op {
+	case OpMkdirAll:
+		for i := len(e.Dirs) - 1; i >= 0; i-- {
+			fi, err := drv.Stat(e.Dirs[i])
+			if err != nil {
+				return err
+			}
+			if !fi.Exists() {
+				continue // already removed
+			}
+			files, err := drv.ReadDir(e.Dirs[i])
+			if err != nil {
+				return err
+			}
+			if len(files) > 0 {
+				continue
+			}
+			if err := drv.Remove(e.Dirs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OpRemove:
+		name := e.Args[0]
+		fi, err := drv.Stat(name)
+		if err != nil {
+			return err
+		}
+		if fi.Exists() {
+			return errWithPath(name, ErrChanged)
+		}
+		return e.Prev.restore(drv, name)
+	case OpRename:
+		oldname, newname := e.Args[0], e.Args[1]
+		fi, err := drv.Stat(oldname)
+		if err != nil {
+			return err
+		}
+		if fi.Exists() {
+			return errWithPath(oldname, ErrChanged)
+		}
+		if err := drv.Rename(newname, oldname); err != nil {
+			return err
+		}
+		return e.Prev.restore(drv, newname)
+	case OpSymlink:
+		oldname, newname := e.Args[0], e.Args[1]
+		fi, err := drv.Stat(newname)
+		if err != nil {
+			return err
+		}
+		if !fi.Exists() {
+			return nil // already removed
+		}
+		if fi.Linkname() != oldname {
+			return errWithPath(newname, ErrChanged)
+		}
+		return drv.Remove(newname)
+	case OpWriteFile:
+		name := e.Args[0]
+		cur, err := journalFile(drv, name)
+		if err != nil {
+			return err
+		}
+		if cur == nil || cur.Linkname != "" || cur.IsDir || !bytes.Equal(cur.Data, e.Data) {
+			return errWithPath(name, ErrChanged)
+		}
+		if e.Prev == nil {
+			return drv.Remove(name)
+		}
+		return e.Prev.restore(drv, name)
+	}
+	return fmt.Errorf("fsutil: unknown journal operation %q", e.Op)
+}
+
+// restore recreates f as name. It does nothing if f is nil.
+func (f *JournalFile) restore(drv fs.Driver, name string) error {
+	switch {
+	case f == nil:
+		return nil
+	case f.Linkname != "":
+		return drv.Symlink(f.Linkname, name)
+	case f.IsDir:
+		return drv.MkdirAll(name)
+	}
+	return drv.WriteFile(name, f.Data, f.Perm)
+}
+
+// ReadJournal reads entries written by a JournalDriver or by WriteJournal.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var (
+		entries []JournalEntry
+		dec     = json.NewDecoder(r)
+	)
+	for {
+		var e JournalEntry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+}
+
+// WriteJournal writes entries to w, one JSON line each, in a single write.
+func WriteJournal(w io.Writer, entries []JournalEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func journalFile(drv fs.Driver, name string) (*JournalFile, error) {
+	fi, err := drv.Stat(name)
+	if err != nil || !fi.Exists() {
+		return nil, err
+	}
+	f := &JournalFile{
+		Perm:     fi.Perm(),
+		Linkname: fi.Linkname(),
+		IsDir:    fi.IsDir() && fi.Linkname() == "",
+	}
+	if f.Linkname == "" && !f.IsDir {
+		if f.Data, err = readRawFile(drv, name); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// RawFileReader is implemented by drivers whose ReadFile may change the content of
+// files, like OSDriver does with newlines, so files can still be read byte for byte.
+type RawFileReader interface {
+	ReadRawFile(filename string) ([]byte, error)
+}
+
+// readRawFile reads filename byte for byte, if drv allows it.
+func readRawFile(drv fs.Driver, filename string) ([]byte, error) {
+	if r, ok := drv.(RawFileReader); ok {
+		return r.ReadRawFile(filename)
+	}
+	return drv.ReadFile(filename)
+}
@@ -0,0 +1,208 @@
+package fsutil_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJournalDriver(t *testing.T) {
+	newDriver := func() *fstest.InMemoryDriver {
+		return &fstest.InMemoryDriver{
+			Files: map[string]fstest.File{
+				"home": {
+					Perm: os.ModePerm,
+					Children: map[string]fstest.File{
+						"dotfiles": {
+							Perm: os.ModePerm,
+							Children: map[string]fstest.File{
+								"pilgo.yml": {Perm: 0o644, Data: []byte("targets: []\n")},
+								"vimrc":     {Perm: 0o644, Data: []byte("set nu\n")},
+								"zshrc":     {Perm: 0o644, Data: []byte("bindkey -v\n")},
+								"old":       {Perm: 0o644, Data: []byte("old\n")},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	var (
+		dotfiles = filepath.Join("home", "dotfiles")
+		vimrc    = filepath.Join(dotfiles, "vimrc")
+		config   = filepath.Join("home", "config")
+	)
+	type call struct {
+		op   string
+		args []string
+		run  func(drv fsutil.JournalDriver) error
+	}
+	calls := []call{
+		{
+			fsutil.OpMkdirAll,
+			[]string{filepath.Join(config, "vim")},
+			func(drv fsutil.JournalDriver) error { return drv.MkdirAll(filepath.Join(config, "vim")) },
+		},
+		{
+			fsutil.OpSymlink,
+			[]string{vimrc, filepath.Join(config, "vim", "vimrc")},
+			func(drv fsutil.JournalDriver) error { return drv.Symlink(vimrc, filepath.Join(config, "vim", "vimrc")) },
+		},
+		{
+			fsutil.OpWriteFile,
+			[]string{filepath.Join(dotfiles, "pilgo.yml")},
+			func(drv fsutil.JournalDriver) error {
+				return drv.WriteFile(filepath.Join(dotfiles, "pilgo.yml"), []byte("targets: [vimrc]\n"), 0o644)
+			},
+		},
+		{
+			fsutil.OpWriteFile,
+			[]string{filepath.Join(dotfiles, "new")},
+			func(drv fsutil.JournalDriver) error {
+				return drv.WriteFile(filepath.Join(dotfiles, "new"), []byte("new\n"), 0o600)
+			},
+		},
+		{
+			fsutil.OpRename,
+			[]string{filepath.Join(dotfiles, "zshrc"), filepath.Join(dotfiles, "old")},
+			func(drv fsutil.JournalDriver) error {
+				return drv.Rename(filepath.Join(dotfiles, "zshrc"), filepath.Join(dotfiles, "old"))
+			},
+		},
+		{
+			fsutil.OpRemove,
+			[]string{vimrc},
+			func(drv fsutil.JournalDriver) error { return drv.Remove(vimrc) },
+		},
+		{
+			fsutil.OpRemove,
+			[]string{filepath.Join(dotfiles, "missing")},
+			func(drv fsutil.JournalDriver) error { return drv.Remove(filepath.Join(dotfiles, "missing")) },
+		},
+	}
+
+	var (
+		inner   = newDriver()
+		journal bytes.Buffer
+		drv     = fsutil.JournalDriver{Inner: inner, Journal: &journal}
+	)
+	for _, c := range calls {
+		err := c.run(drv)
+		if c.args[0] == filepath.Join(dotfiles, "missing") {
+			if err == nil {
+				t.Fatalf("want error removing %s, got nil", c.args[0])
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := fsutil.ReadJournal(&journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := len(calls), len(entries); got != want {
+		t.Fatalf("want %d entries, got %d", want, got)
+	}
+	for i, e := range entries {
+		if want, got := calls[i].op, e.Op; got != want {
+			t.Errorf("entry %d: want op %q, got %q", i, want, got)
+		}
+		if want, got := calls[i].args, e.Args; !cmp.Equal(got, want) {
+			t.Errorf("entry %d: args mismatch (-want +got):\n%s", i, cmp.Diff(want, got))
+		}
+		if e.Time.IsZero() {
+			t.Errorf("entry %d: want time, got zero", i)
+		}
+	}
+	if want, got := []string{config, filepath.Join(config, "vim")}, entries[0].Dirs; !cmp.Equal(got, want) {
+		t.Fatalf("MkdirAll dirs mismatch (-want +got):\n%s", cmp.Diff(want, got))
+	}
+	if entries[len(entries)-1].Error == "" {
+		t.Fatal("want error recorded for last entry, got none")
+	}
+
+	t.Run("Undo", func(t *testing.T) {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if err := entries[i].Undo(inner); err != nil {
+				t.Fatalf("entry %d: %v", i, err)
+			}
+		}
+		if want, got := newDriver().Files, inner.Files; !cmp.Equal(got, want) {
+			t.Fatalf("JournalEntry.Undo mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+	})
+	t.Run("changed", func(t *testing.T) {
+		var (
+			inner   = newDriver()
+			journal bytes.Buffer
+			drv     = fsutil.JournalDriver{Inner: inner, Journal: &journal}
+			name    = filepath.Join(dotfiles, "pilgo.yml")
+		)
+		if err := drv.WriteFile(name, []byte("targets: [vimrc]\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := inner.WriteFile(name, []byte("targets: [zshrc]\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := fsutil.ReadJournal(&journal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = entries[0].Undo(inner)
+		if want, got := fsutil.ErrChanged, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		data, err := inner.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "targets: [zshrc]\n", string(data); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	})
+	t.Run("CRLF", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "pilgo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		crlf := []byte("targets:\r\n- vimrc\r\n")
+		if err := ioutil.WriteFile(filepath.Join(dir, "pilgo.yml"), crlf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var (
+			journal bytes.Buffer
+			inner   = fsutil.RootedDriver{Root: dir, Inner: fsutil.OSDriver{}}
+			drv     = fsutil.JournalDriver{Inner: inner, Journal: &journal}
+		)
+		if err := drv.WriteFile("/pilgo.yml", []byte("targets: []\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := fsutil.ReadJournal(&journal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := crlf, entries[0].Prev.Data; !bytes.Equal(got, want) {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		if err := entries[0].Undo(inner); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "pilgo.yml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := crlf, data; !bytes.Equal(got, want) {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	})
+}
@@ -10,12 +10,14 @@ import (
 	"golang.org/x/text/transform"
 )
 
-// NOTE(gbrlsnchs): Declaring this variable here might prevent concurrent file reads, since
-// this is a stateful transformer used between reads and reset at the beginning of reads.
-var normalize = new(crlf.Normalize)
-
-// OSDriver is the driver for a concrete file system.
-type OSDriver struct{}
+// OSDriver is the driver for a concrete file system. It's safe for concurrent use.
+//
+// By default, CRLF newlines are transformed into LF only when reading files. Set KeepCRLF
+// to read files as they are, for example, when they're binary files. The plg command
+// always normalizes CRLF newlines, since the only files it reads are configuration files.
+type OSDriver struct {
+	KeepCRLF bool
+}
 
 // MkdirAll creates directories recursively or is a NOP when they already exist.
 func (OSDriver) MkdirAll(dirname string) error {
@@ -53,14 +55,31 @@ func (OSDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
 }
 
 // ReadFile returns the content of filename.
-// It always transforms CRLF newlines into LF only.
-func (OSDriver) ReadFile(filename string) ([]byte, error) {
+// It transforms CRLF newlines into LF only, unless KeepCRLF is set.
+func (drv OSDriver) ReadFile(filename string) ([]byte, error) {
+	if drv.KeepCRLF {
+		return ioutil.ReadFile(filename)
+	}
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return ioutil.ReadAll(transform.NewReader(f, normalize))
+	// The transformer is stateful, so each read needs its own.
+	return ioutil.ReadAll(transform.NewReader(f, new(crlf.Normalize)))
+}
+
+// ReadRawFile returns the content of filename as is, even if KeepCRLF is not set.
+func (OSDriver) ReadRawFile(filename string) ([]byte, error) { return ioutil.ReadFile(filename) }
+
+// Remove removes a file or an empty directory.
+func (OSDriver) Remove(filename string) error {
+	return os.Remove(filename)
+}
+
+// Rename moves oldname to newname, replacing newname if it's not a directory.
+func (OSDriver) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
 }
 
 // Stat returns real information about a file.
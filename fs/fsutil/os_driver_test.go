@@ -2,13 +2,16 @@ package fsutil_test
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/andybalholm/crlf"
+	"github.com/gbrlsnchs/pilgo/fs"
 	"github.com/gbrlsnchs/pilgo/fs/fsutil"
 	"golang.org/x/text/transform"
 )
@@ -35,6 +38,8 @@ func TestOSDriver(t *testing.T) {
 	t.Run("MkdirAll", testOSDriverMkdirAll)
 	t.Run("ReadDir", testOSDriverReadDir)
 	t.Run("ReadFile", testOSDriverReadFile)
+	t.Run("Remove", testOSDriverRemove)
+	t.Run("Rename", testOSDriverRename)
 	t.Run("Stat", testOSDriverStat)
 	t.Run("Symlink", testOSDriverSymlink)
 	t.Run("WriteFile", testOSDriverWriteFile)
@@ -148,20 +153,98 @@ func testOSDriverReadDir(t *testing.T) {
 
 func testOSDriverReadFile(t *testing.T) {
 	testCases := []struct {
+		name     string
 		filename string
+		keepCRLF bool
 		want     string
 		err      error
 	}{
 		{
+			name:     "test.txt",
 			filename: "test.txt",
 			want:     "read file test\n",
 			err:      nil,
 		},
 		{
+			name:     "nonexistent.txt",
 			filename: "nonexistent.txt",
 			want:     "",
 			err:      os.ErrNotExist,
 		},
+		{
+			name:     "crlf.txt",
+			filename: "crlf.txt",
+			want:     "read\nfile\ntest\n",
+			err:      nil,
+		},
+		{
+			name:     "keep crlf",
+			filename: "crlf.txt",
+			keepCRLF: true,
+			want:     "read\r\nfile\r\ntest\r\n",
+			err:      nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				drv      = fsutil.OSDriver{KeepCRLF: tc.keepCRLF}
+				filename = filepath.Join("testdata", filepath.Dir(t.Name()), tc.filename)
+			)
+			b, err := drv.ReadFile(filename)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if want, got := tc.want, b; string(got) != want {
+				t.Errorf("want %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+// TestOSDriverConcurrentReadFile is meant to be run with the race detector.
+func TestOSDriverConcurrentReadFile(t *testing.T) {
+	const (
+		workers = 8
+		reads   = 50
+	)
+	var (
+		fs       = fs.New(fsutil.OSDriver{})
+		filename = filepath.Join("testdata", "TestOSDriver", "ReadFile", "crlf.txt")
+		wg       sync.WaitGroup
+		errs     = make(chan error, workers*reads)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < reads; j++ {
+				b, err := fs.ReadFile(filename)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if want, got := "read\nfile\ntest\n", string(b); got != want {
+					errs <- fmt.Errorf("want %q, got %q", want, got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func testOSDriverRemove(t *testing.T) {
+	testCases := []struct {
+		filename string
+		create   bool
+		err      error
+	}{
+		{"file", true, nil},
+		{"nonexistent", false, os.ErrNotExist},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.filename, func(t *testing.T) {
@@ -169,11 +252,57 @@ func testOSDriverReadFile(t *testing.T) {
 				drv      fsutil.OSDriver
 				filename = filepath.Join("testdata", t.Name())
 			)
-			b, err := drv.ReadFile(filename)
+			if tc.create {
+				if err := ioutil.WriteFile(filename, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				defer os.Remove(filename)
+			}
+			err := drv.Remove(filename)
 			if want, got := tc.err, err; !errors.Is(got, want) {
 				t.Fatalf("want %v, got %v", want, got)
 			}
-			if want, got := tc.want, b; string(got) != want {
+			if _, err := os.Lstat(filename); !os.IsNotExist(err) {
+				t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+			}
+		})
+	}
+}
+
+func testOSDriverRename(t *testing.T) {
+	testCases := []struct {
+		oldname, newname string
+		data             string
+		err              error
+	}{
+		{"file", "file_renamed", "rename test\n", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.oldname, func(t *testing.T) {
+			var (
+				drv     fsutil.OSDriver
+				oldname = filepath.Join("testdata", t.Name())
+				newname = filepath.Join(filepath.Dir(oldname), tc.newname)
+			)
+			if err := ioutil.WriteFile(oldname, []byte(tc.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				os.Remove(oldname)
+				os.Remove(newname)
+			}()
+			err := drv.Rename(oldname, newname)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if _, err := os.Lstat(oldname); !os.IsNotExist(err) {
+				t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+			}
+			b, err := ioutil.ReadFile(newname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tc.data, b; string(got) != want {
 				t.Errorf("want %q, got %q", want, got)
 			}
 		})
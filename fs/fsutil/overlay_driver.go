@@ -0,0 +1,363 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotDir   = errors.New("not a directory")
+	errNotEmpty = errors.New("directory not empty")
+)
+
+// OverlayDriver reads files from Lower, but keeps changes to them in memory, as an
+// upper layer on top of Lower, which is never modified. Removed files are hidden by
+// whiteouts in the upper layer. This is useful to simulate changes, like linking
+// dotfiles, and then check the result without touching the real file system.
+//
+// Symlinks created in the upper layer are followed by the driver itself, while symlinks
+// from Lower are followed by Lower. The zero value is an overlay with no changes.
+type OverlayDriver struct {
+	Lower fs.Driver
+	upper map[string]overlayFile
+}
+
+// overlayFile is a file in the upper layer. Files that don't exist are whiteouts, and
+// opaque directories hide the files with the same names from the lower layer.
+type overlayFile struct {
+	info   fileInfo
+	data   []byte
+	opaque bool
+}
+
+// MkdirAll creates directories and their parents in the upper layer, if needed.
+func (drv *OverlayDriver) MkdirAll(dirname string) error {
+	p, err := drv.resolve(dirname)
+	if err != nil {
+		return err
+	}
+	var dirs []string
+	for dir := p; ; dir = filepath.Dir(dir) {
+		fi, err := drv.stat(dir)
+		if err != nil {
+			return err
+		}
+		if fi.Exists() {
+			if !fi.IsDir() && fi.Linkname() == "" {
+				return &os.PathError{Op: "mkdir", Path: dirname, Err: errNotDir}
+			}
+			break
+		}
+		dirs = append(dirs, dir)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		drv.create(dirs[i], overlayFile{info: fileInfo{exists: true, isDir: true, perm: 0o755}})
+	}
+	return nil
+}
+
+// ReadDir lists files from both layers, with the upper one taking precedence.
+func (drv *OverlayDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
+	p, err := drv.resolve(dirname)
+	if err != nil {
+		return nil, err
+	}
+	if p, err = drv.follow(p); err != nil {
+		return nil, err
+	}
+	f, ok := drv.lookup(p)
+	if ok && !f.info.exists {
+		return nil, &os.PathError{Op: "open", Path: dirname, Err: os.ErrNotExist}
+	}
+	if ok && !f.info.isDir {
+		return nil, &os.PathError{Op: "readdirent", Path: dirname, Err: errNotDir}
+	}
+	files := make(map[string]fs.FileInfo)
+	if !ok || !f.opaque {
+		lower, err := drv.Lower.ReadDir(p)
+		if err != nil && !ok {
+			return nil, err
+		}
+		for _, fi := range lower {
+			files[fi.Name()] = fi
+		}
+	}
+	for name, f := range drv.upper {
+		if filepath.Dir(name) != p || name == p {
+			continue
+		}
+		if f.info.exists {
+			files[f.info.name] = f.info
+		} else {
+			delete(files, filepath.Base(name))
+		}
+	}
+	list := make([]fs.FileInfo, 0, len(files))
+	for _, fi := range files {
+		list = append(list, fi)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// ReadFile returns the content of filename from the upper layer or, if it's not
+// there, from the lower one.
+func (drv *OverlayDriver) ReadFile(filename string) ([]byte, error) {
+	p, err := drv.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+	if p, err = drv.follow(p); err != nil {
+		return nil, err
+	}
+	f, ok := drv.lookup(p)
+	if !ok {
+		return drv.Lower.ReadFile(p)
+	}
+	switch {
+	case !f.info.exists:
+		return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+	case f.info.isDir:
+		return nil, &os.PathError{Op: "read", Path: filename, Err: errIsDir}
+	}
+	return append([]byte(nil), f.data...), nil
+}
+
+// Remove hides a file or an empty directory with a whiteout.
+func (drv *OverlayDriver) Remove(filename string) error {
+	p, err := drv.resolve(filename)
+	if err != nil {
+		return err
+	}
+	fi, err := drv.stat(p)
+	if err != nil {
+		return err
+	}
+	if !fi.Exists() {
+		return &os.PathError{Op: "remove", Path: filename, Err: os.ErrNotExist}
+	}
+	if fi.IsDir() {
+		files, err := drv.ReadDir(p)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			return &os.PathError{Op: "remove", Path: filename, Err: errNotEmpty}
+		}
+	}
+	drv.create(p, overlayFile{})
+	return nil
+}
+
+// Rename copies oldname to newname in the upper layer, replacing newname if it's not a
+// directory, and then hides oldname with a whiteout.
+func (drv *OverlayDriver) Rename(oldname, newname string) error {
+	oldpath, err := drv.resolve(oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := drv.resolve(newname)
+	if err != nil {
+		return err
+	}
+	fi, err := drv.stat(oldpath)
+	if err != nil {
+		return err
+	}
+	if !fi.Exists() {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrNotExist}
+	}
+	target, err := drv.stat(newpath)
+	if err != nil {
+		return err
+	}
+	if target.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrExist}
+	}
+	if err := drv.copy(oldpath, newpath, fi); err != nil {
+		return err
+	}
+	drv.create(oldpath, overlayFile{})
+	return nil
+}
+
+// Stat returns information about a file without following it.
+func (drv *OverlayDriver) Stat(filename string) (fs.FileInfo, error) {
+	p, err := drv.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+	return drv.stat(p)
+}
+
+// Symlink creates a symlink in the upper layer.
+func (drv *OverlayDriver) Symlink(oldname, newname string) error {
+	p, err := drv.resolve(newname)
+	if err != nil {
+		return err
+	}
+	if err := drv.checkCreate(p); err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+	drv.create(p, overlayFile{info: fileInfo{exists: true, linkname: oldname, perm: os.ModePerm}})
+	return nil
+}
+
+// WriteFile writes a file to the upper layer. Just like in a real file system, the
+// file keeps its permission if it already exists.
+func (drv *OverlayDriver) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	p, err := drv.resolve(filename)
+	if err != nil {
+		return err
+	}
+	if p, err = drv.follow(p); err != nil {
+		return err
+	}
+	fi, err := drv.stat(p)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fi.IsDir():
+		return &os.PathError{Op: "open", Path: filename, Err: errIsDir}
+	case fi.Exists():
+		perm = fi.Perm()
+	default:
+		if err := drv.checkCreate(p); err != nil {
+			return &os.PathError{Op: "open", Path: filename, Err: err}
+		}
+	}
+	drv.create(p, overlayFile{
+		info: fileInfo{exists: true, perm: perm},
+		data: append([]byte(nil), data...),
+	})
+	return nil
+}
+
+// checkCreate checks whether p can be created, that is, p doesn't exist but its parent does.
+func (drv *OverlayDriver) checkCreate(p string) error {
+	fi, err := drv.stat(p)
+	if err != nil {
+		return err
+	}
+	if fi.Exists() {
+		return os.ErrExist
+	}
+	parent, err := drv.Stat(filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	if !parent.Exists() {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+// copy copies the file at oldpath, whose information is fi, to newpath in the upper layer.
+func (drv *OverlayDriver) copy(oldpath, newpath string, fi fs.FileInfo) error {
+	switch {
+	case fi.Linkname() != "":
+		drv.create(newpath, overlayFile{info: fileInfo{exists: true, linkname: fi.Linkname(), perm: fi.Perm()}})
+	case fi.IsDir():
+		files, err := drv.ReadDir(oldpath)
+		if err != nil {
+			return err
+		}
+		// Files are copied explicitly, so nothing from the lower layer shows up.
+		drv.create(newpath, overlayFile{info: fileInfo{exists: true, isDir: true, perm: fi.Perm()}, opaque: true})
+		for _, child := range files {
+			name := child.Name()
+			if err := drv.copy(filepath.Join(oldpath, name), filepath.Join(newpath, name), child); err != nil {
+				return err
+			}
+		}
+	default:
+		data, err := drv.ReadFile(oldpath)
+		if err != nil {
+			return err
+		}
+		drv.create(newpath, overlayFile{info: fileInfo{exists: true, perm: fi.Perm()}, data: data})
+	}
+	return nil
+}
+
+// create adds f to the upper layer as p, or a whiteout if f doesn't exist.
+// Directories replacing whiteouts are opaque.
+func (drv *OverlayDriver) create(p string, f overlayFile) {
+	if drv.upper == nil {
+		drv.upper = make(map[string]overlayFile)
+	}
+	if prev, ok := drv.upper[p]; ok && !prev.info.exists && f.info.isDir {
+		f.opaque = true
+	}
+	f.info.name = filepath.Base(p)
+	drv.upper[p] = f
+}
+
+// lookup returns the file in the upper layer for p. If p is hidden by a whiteout or by
+// an opaque directory, lookup returns a whiteout. It returns false if p is not in the
+// upper layer, which means it must be read from the lower one.
+func (drv *OverlayDriver) lookup(p string) (overlayFile, bool) {
+	if f, ok := drv.upper[p]; ok {
+		return f, true
+	}
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		if f, ok := drv.upper[dir]; ok && (!f.info.exists || !f.info.isDir || f.opaque) {
+			return overlayFile{}, true
+		}
+		if dir == filepath.Dir(dir) {
+			return overlayFile{}, false
+		}
+	}
+}
+
+func (drv *OverlayDriver) stat(p string) (fs.FileInfo, error) {
+	if f, ok := drv.lookup(p); ok {
+		return f.info, nil
+	}
+	return drv.Lower.Stat(p)
+}
+
+// resolve cleans name and follows symlinks from the upper layer in its parents.
+func (drv *OverlayDriver) resolve(name string) (string, error) {
+	p := filepath.Clean(name)
+	dir := filepath.Dir(p)
+	if dir == p {
+		return p, nil
+	}
+	dir, err := drv.resolve(dir)
+	if err != nil {
+		return "", err
+	}
+	if dir, err = drv.follow(dir); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(p)), nil
+}
+
+// follow follows p while it's a symlink from the upper layer.
+func (drv *OverlayDriver) follow(p string) (string, error) {
+	for i := 0; i < maxLinks; i++ {
+		f, ok := drv.upper[p]
+		if !ok || f.info.linkname == "" {
+			return p, nil
+		}
+		linkname := f.info.linkname
+		if !filepath.IsAbs(linkname) {
+			linkname = filepath.Join(filepath.Dir(p), linkname)
+		}
+		var err error
+		if p, err = drv.resolve(linkname); err != nil {
+			return "", err
+		}
+	}
+	return "", errWithPath(p, ErrTooManyLinks)
+}
@@ -0,0 +1,165 @@
+package fsutil_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOverlayDriver(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "pilgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	var (
+		home     = filepath.Join(tmp, "home")
+		dotfiles = filepath.Join(home, "dotfiles")
+	)
+	if err := os.MkdirAll(filepath.Join(dotfiles, "zsh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(dotfiles, "pilgo.yml"):    "targets: []\n",
+		filepath.Join(dotfiles, "vimrc"):        "set nu\n",
+		filepath.Join(dotfiles, "zsh", "zshrc"): "bindkey -v\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// list returns the names of files in dir as seen by drv.
+	list := func(t *testing.T, drv *fsutil.OverlayDriver, dir string) []string {
+		files, err := drv.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, fi := range files {
+			names = append(names, fi.Name())
+		}
+		return names
+	}
+	read := func(t *testing.T, drv *fsutil.OverlayDriver, name string) string {
+		data, err := drv.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("Symlink", func(t *testing.T) {
+		drv := &fsutil.OverlayDriver{Lower: fsutil.OSDriver{}}
+		config := filepath.Join(home, "config")
+		if err := drv.MkdirAll(config); err != nil {
+			t.Fatal(err)
+		}
+		if err := drv.Symlink(filepath.Join(dotfiles, "zsh"), filepath.Join(config, "zsh")); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := drv.Stat(filepath.Join(config, "zsh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.Join(dotfiles, "zsh"), fi.Linkname(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		if want, got := []string{"config", "dotfiles"}, list(t, drv, home); !cmp.Equal(got, want) {
+			t.Fatalf("OverlayDriver.ReadDir mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+		if want, got := "bindkey -v\n", read(t, drv, filepath.Join(config, "zsh", "zshrc")); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		err = drv.Symlink(filepath.Join(dotfiles, "vimrc"), filepath.Join(config, "zsh"))
+		if want, got := os.ErrExist, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		err = drv.Symlink(filepath.Join(dotfiles, "vimrc"), filepath.Join(home, "missing", "vimrc"))
+		if want, got := os.ErrNotExist, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+	t.Run("WriteFile", func(t *testing.T) {
+		drv := &fsutil.OverlayDriver{Lower: fsutil.OSDriver{}}
+		name := filepath.Join(dotfiles, "pilgo.yml")
+		if err := drv.WriteFile(name, []byte("targets: [vimrc]\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "targets: [vimrc]\n", read(t, drv, name); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		fi, err := drv.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := os.FileMode(0o644), fi.Perm(); got != want {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+	t.Run("Remove", func(t *testing.T) {
+		drv := &fsutil.OverlayDriver{Lower: fsutil.OSDriver{}}
+		err := drv.Remove(filepath.Join(dotfiles, "zsh"))
+		if err == nil {
+			t.Fatal("want error removing non-empty directory, got nil")
+		}
+		if err := drv.Remove(filepath.Join(dotfiles, "zsh", "zshrc")); err != nil {
+			t.Fatal(err)
+		}
+		if err := drv.Remove(filepath.Join(dotfiles, "zsh")); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := []string{"pilgo.yml", "vimrc"}, list(t, drv, dotfiles); !cmp.Equal(got, want) {
+			t.Fatalf("OverlayDriver.ReadDir mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+		fi, err := drv.Stat(filepath.Join(dotfiles, "zsh", "zshrc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Exists() {
+			t.Fatal("want removed file to not exist")
+		}
+		// Directories recreated after being removed don't show old files.
+		if err := drv.MkdirAll(filepath.Join(dotfiles, "zsh")); err != nil {
+			t.Fatal(err)
+		}
+		if got := list(t, drv, filepath.Join(dotfiles, "zsh")); len(got) > 0 {
+			t.Fatalf("want empty directory, got %v", got)
+		}
+	})
+	t.Run("Rename", func(t *testing.T) {
+		drv := &fsutil.OverlayDriver{Lower: fsutil.OSDriver{}}
+		if err := drv.Rename(filepath.Join(dotfiles, "zsh"), filepath.Join(dotfiles, "zsh2")); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := []string{"pilgo.yml", "vimrc", "zsh2"}, list(t, drv, dotfiles); !cmp.Equal(got, want) {
+			t.Fatalf("OverlayDriver.ReadDir mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+		if want, got := "bindkey -v\n", read(t, drv, filepath.Join(dotfiles, "zsh2", "zshrc")); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		_, err := drv.ReadFile(filepath.Join(dotfiles, "zsh", "zshrc"))
+		if want, got := os.ErrNotExist, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	// The lower layer is never modified.
+	for name, data := range files {
+		got, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := data; string(got) != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, "config")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+	}
+}
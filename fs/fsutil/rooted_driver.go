@@ -0,0 +1,256 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+)
+
+// maxLinks is the maximum number of symlinks followed when resolving a path.
+const maxLinks = 40
+
+var (
+	// ErrEscape means a path resolves to somewhere outside the root directory.
+	ErrEscape = errors.New("path escapes root directory")
+	// ErrTooManyLinks means resolving a path requires following too many symlinks.
+	ErrTooManyLinks = errors.New("too many levels of symbolic links")
+)
+
+const sep = string(filepath.Separator)
+
+// RootedDriver confines every operation of Inner to Root, which works as the root of the
+// file system. Absolute paths are placed inside Root and relative ones are relative to Dir,
+// which is itself inside Root. Just like in a real root directory, ".." in Root is Root
+// itself, and symlinks that point outside Root are rejected. Symlinks created inside Root
+// point inside Root too, but their link names are reported as if Root were the real root.
+// On Windows, volume names become directories inside Root.
+//
+// Root must be an absolute path in Inner.
+type RootedDriver struct {
+	Root  string
+	Dir   string
+	Inner fs.Driver
+}
+
+// MkdirAll creates directories and their parents inside Root.
+func (drv RootedDriver) MkdirAll(dirname string) error {
+	p, err := drv.resolve(dirname, true)
+	if err != nil {
+		return err
+	}
+	return drv.Inner.MkdirAll(p)
+}
+
+// ReadDir lists files inside Root.
+func (drv RootedDriver) ReadDir(dirname string) ([]fs.FileInfo, error) {
+	p, err := drv.resolve(dirname, true)
+	if err != nil {
+		return nil, err
+	}
+	files, err := drv.Inner.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	for i, fi := range files {
+		files[i] = drv.fileInfo(fi)
+	}
+	return files, nil
+}
+
+// ReadFile reads a file inside Root.
+func (drv RootedDriver) ReadFile(filename string) ([]byte, error) {
+	p, err := drv.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+	return drv.Inner.ReadFile(p)
+}
+
+// ReadRawFile reads a file inside Root byte for byte, if Inner allows it.
+func (drv RootedDriver) ReadRawFile(filename string) ([]byte, error) {
+	p, err := drv.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+	return readRawFile(drv.Inner, p)
+}
+
+// Remove removes a file inside Root.
+func (drv RootedDriver) Remove(filename string) error {
+	p, err := drv.resolve(filename, false)
+	if err != nil {
+		return err
+	}
+	return drv.Inner.Remove(p)
+}
+
+// Rename moves a file inside Root.
+func (drv RootedDriver) Rename(oldname, newname string) error {
+	oldpath, err := drv.resolve(oldname, false)
+	if err != nil {
+		return err
+	}
+	newpath, err := drv.resolve(newname, false)
+	if err != nil {
+		return err
+	}
+	return drv.Inner.Rename(oldpath, newpath)
+}
+
+// Stat returns information about a file inside Root.
+func (drv RootedDriver) Stat(filename string) (fs.FileInfo, error) {
+	p, err := drv.resolve(filename, false)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := drv.Inner.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	return drv.fileInfo(fi), nil
+}
+
+// Symlink creates a symlink inside Root. If oldname is absolute, it's also placed inside
+// Root, otherwise it's kept relative, as long as it doesn't point outside Root.
+func (drv RootedDriver) Symlink(oldname, newname string) error {
+	newpath, err := drv.resolve(newname, false)
+	if err != nil {
+		return err
+	}
+	if isAbs(oldname) {
+		elems := drv.split(oldname)
+		oldname = filepath.Join(append([]string{drv.Root}, elems...)...)
+	} else if !drv.contains(filepath.Join(filepath.Dir(newpath), oldname)) {
+		return errWithPath(oldname, ErrEscape)
+	}
+	return drv.Inner.Symlink(oldname, newpath)
+}
+
+// WriteFile writes a file inside Root.
+func (drv RootedDriver) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	p, err := drv.resolve(filename, true)
+	if err != nil {
+		return err
+	}
+	return drv.Inner.WriteFile(p, data, perm)
+}
+
+// Unroot returns the path inside Root that corresponds to path in Inner.
+// It returns ErrEscape if path is not inside Root.
+func (drv RootedDriver) Unroot(path string) (string, error) {
+	if !drv.contains(path) {
+		return "", errWithPath(path, ErrEscape)
+	}
+	var (
+		rel   = strings.TrimPrefix(strings.TrimPrefix(filepath.Clean(path), drv.Root), sep)
+		first = rel
+	)
+	if i := strings.Index(rel, sep); i >= 0 {
+		first = rel[:i]
+	}
+	if drive := first + ":"; filepath.VolumeName(drive) == drive {
+		return drive + sep + strings.TrimPrefix(rel[len(first):], sep), nil
+	}
+	return sep + rel, nil
+}
+
+// resolve returns the path in Inner for name, following symlinks in its parents.
+// When follow is true, the last element of name is also followed if it's a symlink.
+func (drv RootedDriver) resolve(name string, follow bool) (string, error) {
+	var (
+		elems = drv.split(name)
+		p     = drv.Root
+		err   error
+	)
+	for i, e := range elems {
+		p = filepath.Join(p, e)
+		if i == len(elems)-1 && !follow {
+			break
+		}
+		if p, err = drv.follow(name, p); err != nil {
+			return "", err
+		}
+	}
+	return p, nil
+}
+
+// follow follows p until it's not a symlink. Symlinks that point outside Root are rejected.
+func (drv RootedDriver) follow(name, p string) (string, error) {
+	for i := 0; i < maxLinks; i++ {
+		fi, err := drv.Inner.Stat(p)
+		if err != nil {
+			return "", err
+		}
+		linkname := fi.Linkname()
+		if linkname == "" {
+			return p, nil
+		}
+		if !drv.contains(linkname) {
+			if isAbs(linkname) {
+				return "", errWithPath(name, ErrEscape)
+			}
+			linkname = filepath.Join(filepath.Dir(p), linkname)
+			if !drv.contains(linkname) {
+				return "", errWithPath(name, ErrEscape)
+			}
+		}
+		p = filepath.Clean(linkname)
+	}
+	return "", errWithPath(name, ErrTooManyLinks)
+}
+
+// split returns the elements of name relative to Root. If name is relative, it's
+// relative to Dir. Volume names, if any, are the first element.
+func (drv RootedDriver) split(name string) []string {
+	vol := filepath.VolumeName(name)
+	rest := name[len(vol):]
+	if vol == "" && !isAbs(rest) && drv.Dir != "" {
+		root := drv
+		root.Dir = ""
+		dir := root.split(drv.Dir)
+		return root.split(sep + filepath.Join(append(dir, rest)...))
+	}
+	// Just like in the real root, ".." in the root is the root itself.
+	rest = filepath.Clean(sep + rest)
+	rest = strings.TrimLeft(rest, `/`+sep)
+	var elems []string
+	if vol != "" {
+		elems = append(elems, strings.Trim(strings.TrimSuffix(vol, ":"), `/`+sep))
+	}
+	if rest != "" {
+		elems = append(elems, strings.Split(rest, sep)...)
+	}
+	return elems
+}
+
+func (drv RootedDriver) contains(p string) bool {
+	p = filepath.Clean(p)
+	return p == drv.Root || strings.HasPrefix(p, strings.TrimSuffix(drv.Root, sep)+sep)
+}
+
+func (drv RootedDriver) fileInfo(fi fs.FileInfo) fs.FileInfo {
+	linkname := fi.Linkname()
+	if linkname == "" || !drv.contains(linkname) {
+		return fi
+	}
+	linkname, _ = drv.Unroot(linkname)
+	return rootedFileInfo{fi, linkname}
+}
+
+// rootedFileInfo is a symlink whose link name is translated from a RootedDriver's inner driver.
+type rootedFileInfo struct {
+	fs.FileInfo
+	linkname string
+}
+
+func (fi rootedFileInfo) Linkname() string { return fi.linkname }
+
+func isAbs(name string) bool {
+	return filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, sep)
+}
+
+func errWithPath(path string, err error) error { return fmt.Errorf("fsutil: %s: %w", path, err) }
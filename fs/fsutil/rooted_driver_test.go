@@ -0,0 +1,152 @@
+package fsutil_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/fs/fsutil"
+)
+
+func TestRootedDriver(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "pilgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	var (
+		root     = filepath.Join(tmp, "root")
+		dotfiles = filepath.Join(root, "home", "dotfiles")
+	)
+	if err := os.MkdirAll(dotfiles, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(tmp, "secret"):   "secret",
+		filepath.Join(dotfiles, "foo"): "foo",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		filepath.Join(root, "home", "escape"):   filepath.Join(tmp, "secret"),
+		filepath.Join(root, "home", "relative"): filepath.FromSlash("../../secret"),
+		filepath.Join(root, "home", "inside"):   dotfiles,
+	}
+	for name, linkname := range links {
+		if err := os.Symlink(linkname, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newDriver := func() fsutil.RootedDriver {
+		return fsutil.RootedDriver{
+			Root:  root,
+			Dir:   filepath.FromSlash("/home/dotfiles"),
+			Inner: fsutil.OSDriver{},
+		}
+	}
+
+	t.Run("ReadFile", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			want string
+			err  error
+		}{
+			{"foo", "foo", nil},
+			{filepath.FromSlash("/home/dotfiles/foo"), "foo", nil},
+			{filepath.FromSlash("../dotfiles/foo"), "foo", nil},
+			{filepath.FromSlash("/home/inside/foo"), "foo", nil},
+			{filepath.FromSlash("/../../secret"), "", os.ErrNotExist},
+			{filepath.FromSlash("../../../secret"), "", os.ErrNotExist},
+			{filepath.FromSlash("/home/escape"), "", fsutil.ErrEscape},
+			{filepath.FromSlash("/home/relative"), "", fsutil.ErrEscape},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				data, err := newDriver().ReadFile(tc.name)
+				if want, got := tc.err, err; !errors.Is(got, want) {
+					t.Fatalf("want %v, got %v", want, got)
+				}
+				if want, got := tc.want, string(data); got != want {
+					t.Fatalf("want %q, got %q", want, got)
+				}
+			})
+		}
+	})
+	t.Run("Stat", func(t *testing.T) {
+		drv := newDriver()
+		fi, err := drv.Stat(filepath.FromSlash("/home/inside"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.FromSlash("/home/dotfiles"), fi.Linkname(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		// Symlinks are not followed, so escaping ones can be inspected.
+		fi, err = drv.Stat(filepath.FromSlash("/home/escape"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.Join(tmp, "secret"), fi.Linkname(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	})
+	t.Run("Symlink", func(t *testing.T) {
+		drv := newDriver()
+		if err := drv.Symlink(filepath.FromSlash("/home/dotfiles/foo"), filepath.FromSlash("/home/foo")); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := drv.Inner.Stat(filepath.Join(root, "home", "foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.Join(dotfiles, "foo"), fi.Linkname(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		if fi, err = drv.Stat(filepath.FromSlash("/home/foo")); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.FromSlash("/home/dotfiles/foo"), fi.Linkname(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		err = drv.Symlink(filepath.FromSlash("../../../secret"), "bar")
+		if want, got := fsutil.ErrEscape, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+	t.Run("WriteFile", func(t *testing.T) {
+		drv := newDriver()
+		if err := drv.WriteFile("bar", []byte("bar"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		data, err := drv.Inner.ReadFile(filepath.Join(dotfiles, "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "bar", string(data); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		err = drv.WriteFile(filepath.FromSlash("/home/escape"), []byte("bar"), 0o644)
+		if want, got := fsutil.ErrEscape, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+	t.Run("Unroot", func(t *testing.T) {
+		drv := newDriver()
+		p, err := drv.Unroot(filepath.Join(root, "home"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := filepath.FromSlash("/home"), p; got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		_, err = drv.Unroot(filepath.Join(tmp, "secret"))
+		if want, got := fsutil.ErrEscape, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
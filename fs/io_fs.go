@@ -0,0 +1,264 @@
+// +build go1.16
+
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	iofs "io/fs"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// maxLinks is the maximum number of symlinks followed when resolving a name.
+const maxLinks = 40
+
+var (
+	errTooManyLinks = errors.New("too many levels of symbolic links")
+	errNotLink      = errors.New("not a symbolic link")
+	errIsDir        = errors.New("is a directory")
+	errNotDir       = errors.New("not a directory")
+)
+
+// DirFS returns an io/fs view of the file tree rooted at dir, just like os.DirFS.
+// Opening or getting information about a symlink follows it, while ReadLink and
+// Lstat don't. Relative symlinks are resolved from the directory they're in, and
+// absolute ones are resolved by the driver, so they may point outside dir.
+//
+// Since drivers don't know file sizes, getting information about a file reads it.
+func (fs FileSystem) DirFS(dir string) iofs.FS {
+	fs.testDriver()
+	return dirFS{fs, dir}
+}
+
+type dirFS struct {
+	fs  FileSystem
+	dir string
+}
+
+// Open opens name for reading, following it if it's a symlink.
+func (d dirFS) Open(name string) (iofs.File, error) {
+	p, fi, err := d.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		info, err := d.info(path.Base(name), p, fi)
+		if err != nil {
+			return nil, err
+		}
+		return &dirFile{d: d, path: p, name: name, info: info}, nil
+	}
+	data, err := d.fs.ReadFile(p)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: err}
+	}
+	info := fileInfo{path.Base(name), fi.Perm(), int64(len(data))}
+	return &file{bytes.NewReader(data), info}, nil
+}
+
+// ReadDir lists the files inside name, sorted by their names.
+func (d dirFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	p, fi, err := d.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+	return d.readDir(name, p)
+}
+
+// ReadFile returns the content of name, following it if it's a symlink.
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	p, fi, err := d.stat("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, &iofs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+	data, err := d.fs.ReadFile(p)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// Stat returns information about name, following it if it's a symlink.
+func (d dirFS) Stat(name string) (iofs.FileInfo, error) {
+	p, fi, err := d.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.info(path.Base(name), p, fi)
+}
+
+// Lstat returns information about name without following it.
+func (d dirFS) Lstat(name string) (iofs.FileInfo, error) {
+	p, fi, err := d.lstat("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.info(path.Base(name), p, fi)
+}
+
+// ReadLink returns the destination of the symlink name.
+func (d dirFS) ReadLink(name string) (string, error) {
+	_, fi, err := d.lstat("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if fi.Linkname() == "" {
+		return "", &iofs.PathError{Op: "readlink", Path: name, Err: errNotLink}
+	}
+	return fi.Linkname(), nil
+}
+
+func (d dirFS) lstat(op, name string) (string, FileInfo, error) {
+	if !iofs.ValidPath(name) {
+		return "", nil, &iofs.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+	p := filepath.Join(d.dir, filepath.FromSlash(name))
+	fi, err := d.fs.Stat(p)
+	if err != nil {
+		return "", nil, &iofs.PathError{Op: op, Path: name, Err: err}
+	}
+	if !fi.Exists() {
+		return "", nil, &iofs.PathError{Op: op, Path: name, Err: iofs.ErrNotExist}
+	}
+	return p, fi, nil
+}
+
+func (d dirFS) stat(op, name string) (string, FileInfo, error) {
+	p, fi, err := d.lstat(op, name)
+	for i := 0; err == nil && fi.Linkname() != ""; i++ {
+		if i == maxLinks {
+			return "", nil, &iofs.PathError{Op: op, Path: name, Err: errTooManyLinks}
+		}
+		link := fi.Linkname()
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(p), link)
+		}
+		p = link
+		if fi, err = d.fs.Stat(p); err != nil {
+			return "", nil, &iofs.PathError{Op: op, Path: name, Err: err}
+		}
+		if !fi.Exists() {
+			return "", nil, &iofs.PathError{Op: op, Path: name, Err: iofs.ErrNotExist}
+		}
+	}
+	return p, fi, err
+}
+
+func (d dirFS) readDir(name, p string) ([]iofs.DirEntry, error) {
+	files, err := d.fs.ReadDir(p)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]iofs.DirEntry, len(files))
+	for i, fi := range files {
+		entries[i] = dirEntry{d, filepath.Join(p, fi.Name()), fi}
+	}
+	return entries, nil
+}
+
+// info returns information about the file p, which is named name in d.
+// Symlinks are never followed, so the information is about them.
+func (d dirFS) info(name, p string, fi FileInfo) (iofs.FileInfo, error) {
+	info := fileInfo{name: name, mode: fi.Perm()}
+	switch {
+	case fi.Linkname() != "":
+		info.mode |= iofs.ModeSymlink
+	case fi.IsDir():
+		info.mode |= iofs.ModeDir
+	default:
+		data, err := d.fs.ReadFile(p)
+		if err != nil {
+			return nil, &iofs.PathError{Op: "stat", Path: name, Err: err}
+		}
+		info.size = int64(len(data))
+	}
+	return info, nil
+}
+
+type dirEntry struct {
+	d    dirFS
+	path string
+	fi   FileInfo
+}
+
+func (e dirEntry) Name() string                 { return e.fi.Name() }
+func (e dirEntry) IsDir() bool                  { return e.fi.IsDir() && e.fi.Linkname() == "" }
+func (e dirEntry) Type() iofs.FileMode          { return e.mode().Type() }
+func (e dirEntry) Info() (iofs.FileInfo, error) { return e.d.info(e.fi.Name(), e.path, e.fi) }
+
+func (e dirEntry) mode() iofs.FileMode {
+	switch {
+	case e.fi.Linkname() != "":
+		return iofs.ModeSymlink
+	case e.fi.IsDir():
+		return iofs.ModeDir
+	}
+	return 0
+}
+
+type fileInfo struct {
+	name string
+	mode iofs.FileMode
+	size int64
+}
+
+func (fi fileInfo) Name() string        { return fi.name }
+func (fi fileInfo) Size() int64         { return fi.size }
+func (fi fileInfo) Mode() iofs.FileMode { return fi.mode }
+func (fi fileInfo) ModTime() time.Time  { return time.Time{} }
+func (fi fileInfo) IsDir() bool         { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() interface{}    { return nil }
+
+type file struct {
+	*bytes.Reader
+	info iofs.FileInfo
+}
+
+func (f *file) Stat() (iofs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error                 { return nil }
+
+// dirFile is an open directory. Its files are only listed when they're read.
+type dirFile struct {
+	d       dirFS
+	path    string
+	name    string
+	info    iofs.FileInfo
+	entries []iofs.DirEntry
+	read    bool
+}
+
+func (f *dirFile) Stat() (iofs.FileInfo, error) { return f.info, nil }
+func (f *dirFile) Close() error                 { return nil }
+
+func (f *dirFile) Read(_ []byte) (int, error) {
+	return 0, &iofs.PathError{Op: "read", Path: f.name, Err: errIsDir}
+}
+
+// ReadDir lists the next n files in the directory, or all of the remaining ones if n <= 0.
+func (f *dirFile) ReadDir(n int) ([]iofs.DirEntry, error) {
+	if !f.read {
+		entries, err := f.d.readDir(f.name, f.path)
+		if err != nil {
+			return nil, err
+		}
+		f.entries, f.read = entries, true
+	}
+	if n > 0 && len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n <= 0 || n > len(f.entries) {
+		n = len(f.entries)
+	}
+	entries := f.entries[:n]
+	f.entries = f.entries[n:]
+	return entries, nil
+}
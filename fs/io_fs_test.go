@@ -0,0 +1,128 @@
+// +build go1.16
+
+package fs_test
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"testing"
+	iofstest "testing/fstest"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+)
+
+func TestFileSystemDirFS(t *testing.T) {
+	drv := fstest.InMemoryDriver{
+		CurrentDir: "",
+		Files: map[string]fstest.File{
+			"dotfiles": {
+				Linkname: "",
+				Perm:     0o755,
+				Data:     nil,
+				Children: map[string]fstest.File{
+					"bar": {
+						Linkname: "",
+						Perm:     0o755,
+						Data:     nil,
+						Children: map[string]fstest.File{
+							"baz": {
+								Linkname: "",
+								Perm:     0o644,
+								Data:     []byte("baz"),
+								Children: nil,
+							},
+							"empty": {
+								Linkname: "",
+								Perm:     0o755,
+								Data:     nil,
+								Children: map[string]fstest.File{},
+							},
+						},
+					},
+					"foo": {
+						Linkname: "",
+						Perm:     0o644,
+						Data:     []byte("foo"),
+						Children: nil,
+					},
+					"foolink": {
+						Linkname: "foo",
+						Perm:     0o777,
+						Data:     nil,
+						Children: nil,
+					},
+				},
+			},
+		},
+	}
+	fsys := fs.New(&drv).DirFS(fstest.AbsPath("dotfiles"))
+	if err := iofstest.TestFS(fsys, "bar/baz", "bar/empty", "foo", "foolink"); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("ReadLink", func(t *testing.T) {
+		linkname, err := iofs.ReadLink(fsys, "foolink")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "foo", linkname; got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		if _, err := iofs.ReadLink(fsys, "foo"); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+	testCases := []struct {
+		name string
+		mode iofs.FileMode
+		size int64
+		err  error
+	}{
+		{"foo", 0o644, 3, nil},
+		{"foolink", 0o644, 3, nil},
+		{"bar", iofs.ModeDir | 0o755, 0, nil},
+		{"missing", 0, 0, iofs.ErrNotExist},
+		{"../foo", 0, 0, iofs.ErrInvalid},
+		{"/foo", 0, 0, iofs.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run("Stat "+tc.name, func(t *testing.T) {
+			fi, err := iofs.Stat(fsys, tc.name)
+			if want, got := tc.err, err; !errors.Is(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+			if want, got := tc.mode, fi.Mode(); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+			if want, got := tc.size, fi.Size(); got != want {
+				t.Errorf("want %d, got %d", want, got)
+			}
+		})
+	}
+	t.Run("Stat loop", func(t *testing.T) {
+		drv.Files["dotfiles"].Children["loop"] = fstest.File{
+			Linkname: "loop",
+			Perm:     0o777,
+			Data:     nil,
+			Children: nil,
+		}
+		if _, err := iofs.Stat(fsys, "loop"); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+	t.Run("Lstat", func(t *testing.T) {
+		fi, err := fsys.(interface {
+			Lstat(string) (iofs.FileInfo, error)
+		}).Lstat("foolink")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := iofs.ModeSymlink|os.ModePerm, fi.Mode(); got != want {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrReadOnly is the error for modifying a read-only file system.
+var ErrReadOnly = errors.New("read-only file system")
+
+// ReadOnly returns a driver that reads files with drv but refuses to modify them.
+// Every method that would modify a file fails with ErrReadOnly instead.
+func ReadOnly(drv Driver) Driver {
+	return readOnlyDriver{drv}
+}
+
+type readOnlyDriver struct {
+	drv Driver
+}
+
+func (ro readOnlyDriver) MkdirAll(dirname string) error {
+	return errReadOnly(dirname)
+}
+
+func (ro readOnlyDriver) ReadDir(dirname string) ([]FileInfo, error) {
+	return ro.drv.ReadDir(dirname)
+}
+
+func (ro readOnlyDriver) ReadFile(filename string) ([]byte, error) {
+	return ro.drv.ReadFile(filename)
+}
+
+func (ro readOnlyDriver) Remove(filename string) error {
+	return errReadOnly(filename)
+}
+
+func (ro readOnlyDriver) Rename(_, newname string) error {
+	return errReadOnly(newname)
+}
+
+func (ro readOnlyDriver) Stat(filename string) (FileInfo, error) {
+	return ro.drv.Stat(filename)
+}
+
+func (ro readOnlyDriver) Symlink(_, newname string) error {
+	return errReadOnly(newname)
+}
+
+func (ro readOnlyDriver) WriteFile(filename string, _ []byte, _ os.FileMode) error {
+	return errReadOnly(filename)
+}
+
+func errReadOnly(name string) error { return fmt.Errorf("fs: %s: %w", name, ErrReadOnly) }
@@ -0,0 +1,70 @@
+package fs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/fs"
+	"github.com/gbrlsnchs/pilgo/fs/fstest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadOnly(t *testing.T) {
+	newDriver := func() *fstest.InMemoryDriver {
+		return &fstest.InMemoryDriver{
+			CurrentDir: "",
+			Files: map[string]fstest.File{
+				"foo": {Data: []byte("foo")},
+				"bar": {Children: map[string]fstest.File{}},
+			},
+		}
+	}
+	t.Run("read", func(t *testing.T) {
+		fsys := fs.New(fs.ReadOnly(newDriver()))
+		data, err := fsys.ReadFile("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "foo", string(data); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		files, err := fsys.ReadDir("bar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := 0, len(files); got != want {
+			t.Fatalf("want %d, got %d", want, got)
+		}
+		fi, err := fsys.Stat("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := true, fi.Exists(); got != want {
+			t.Fatalf("want %t, got %t", want, got)
+		}
+	})
+	t.Run("write", func(t *testing.T) {
+		drv := newDriver()
+		fsys := fs.New(fs.ReadOnly(drv))
+		testCases := []struct {
+			name string
+			call func() error
+		}{
+			{"MkdirAll", func() error { return fsys.MkdirAll("baz") }},
+			{"Remove", func() error { return fsys.Remove("foo") }},
+			{"Rename", func() error { return fsys.Rename("foo", "baz") }},
+			{"Symlink", func() error { return fsys.Symlink("foo", "baz") }},
+			{"WriteFile", func() error { return fsys.WriteFile("foo", []byte("baz"), 0o644) }},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if want, got := fs.ErrReadOnly, tc.call(); !errors.Is(got, want) {
+					t.Fatalf("want %v, got %v", want, got)
+				}
+			})
+		}
+		if want, got := newDriver().Files, drv.Files; !cmp.Equal(got, want) {
+			t.Fatalf("read-only driver mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+	})
+}
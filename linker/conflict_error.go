@@ -3,6 +3,7 @@ package linker
 import "fmt"
 
 // ConflictError represents a group of errors considered as a conflict.
+// Each error is a *NodeError.
 type ConflictError struct {
 	Errs []error
 }
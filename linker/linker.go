@@ -1,8 +1,11 @@
 package linker
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
 
 	"github.com/gbrlsnchs/pilgo/fs"
 	"github.com/gbrlsnchs/pilgo/parser"
@@ -21,19 +24,46 @@ var (
 
 // Linker is a file symlinker.
 type Linker struct {
-	fs fs.FileSystem
+	fs   fs.FileSystem
+	jobs int
+	obs  Observer
 }
 
 // New creates a new linker with a given file system.
-func New(fs fs.FileSystem) *Linker { return &Linker{fs} }
+func New(fs fs.FileSystem, opts ...Option) *Linker {
+	ln := &Linker{fs: fs, jobs: 1}
+	for _, opt := range opts {
+		opt(ln)
+	}
+	return ln
+}
+
+// Option is a functional option that modifies a Linker.
+type Option func(*Linker)
+
+// Jobs sets how many nodes are resolved at the same time. If n is less than 1,
+// it uses the number of CPUs. By default, nodes are resolved one at a time.
+func Jobs(n int) Option {
+	return func(ln *Linker) {
+		if n < 1 {
+			n = runtime.NumCPU()
+		}
+		ln.jobs = n
+	}
+}
 
 // Link creates every symlink needed in tr. Before creating any symlinks,
 // it resolves nodes and checks for conflicts. If any conflicts or errors
 // are found, it aborts the operation.
 //
 // Also, if needed, it creates parent directories if those don't already exist.
-func (ln *Linker) Link(tr *parser.Tree) error {
-	err := ln.Resolve(tr)
+func (ln *Linker) Link(tr *parser.Tree) error { return ln.LinkContext(context.Background(), tr) }
+
+// LinkContext is like Link, but it stops and returns ctx's error as soon as ctx is done.
+// Cancellation is only checked between links, so no link is left half-created, but
+// links created until then are kept.
+func (ln *Linker) LinkContext(ctx context.Context, tr *parser.Tree) error {
+	err := ln.ResolveContext(ctx, tr)
 	if err != nil {
 		return err
 	}
@@ -50,37 +80,77 @@ func (ln *Linker) Link(tr *parser.Tree) error {
 		return err
 	}
 	for _, link := range links {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		tgpath := link[0]
 		lnpath := link[1]
 		parent := lnpath.Dir()
-		if err := ln.fs.MkdirAll(parent); err != nil {
+		if err := ln.mkdirAll(parent); err != nil {
 			return err
 		}
 		if err := ln.fs.Symlink(tgpath.FullPath(), lnpath.FullPath()); err != nil {
 			return err
 		}
+		if ln.obs != nil {
+			ln.obs.SymlinkCreated(tgpath.FullPath(), lnpath.FullPath())
+		}
 	}
 	return nil
 }
 
-// Resolve checks and resolves nodes in a parsed tree.
-func (ln *Linker) Resolve(tr *parser.Tree) error {
+// mkdirAll creates dirname and its parents, if needed. If there's an observer,
+// it's notified of every directory that didn't exist, from the outermost one.
+func (ln *Linker) mkdirAll(dirname string) error {
+	if ln.obs == nil {
+		return ln.fs.MkdirAll(dirname)
+	}
+	var missing []string
+	for dir := dirname; ; dir = filepath.Dir(dir) {
+		fi, err := ln.fs.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if fi.Exists() {
+			break
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if err := ln.fs.MkdirAll(dirname); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		ln.obs.DirCreated(missing[i])
+	}
+	return nil
+}
+
+// Resolve checks and resolves nodes in a parsed tree. Nodes are resolved
+// concurrently if the linker has more than one job, but conflicts are always
+// reported in the same order the tree is walked.
+func (ln *Linker) Resolve(tr *parser.Tree) error { return ln.ResolveContext(context.Background(), tr) }
+
+// ResolveContext is like Resolve, but it stops resolving nodes and returns ctx's error
+// as soon as ctx is done.
+func (ln *Linker) ResolveContext(ctx context.Context, tr *parser.Tree) error {
+	errs := ln.resolveAll(ctx, tr)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	cft := new(ConflictError)
 	err := tr.Walk(func(n *parser.Node) error {
-		err := ln.resolve(n)
-		switch {
-		case errors.Is(err, ErrLinkExist):
-			fallthrough
-		case errors.Is(err, ErrLinkNotExpand):
-			fallthrough
-		case errors.Is(err, ErrTargetNotExist):
-			fallthrough
-		case errors.Is(err, ErrTargetNotExpand):
-			cft.Errs = append(cft.Errs, err)
-			return nil
-		default:
+		err := errs[n]
+		if !isConflict(err) {
 			return err
 		}
+		cft.Errs = append(cft.Errs, err)
+		if ln.obs != nil {
+			ln.obs.ConflictFound(n, err)
+		}
+		return nil
 	})
 	if err != nil {
 		return err
@@ -91,6 +161,87 @@ func (ln *Linker) Resolve(tr *parser.Tree) error {
 	return nil
 }
 
+// resolveAll resolves every node in tr, including nodes added by expansions, and
+// returns errors by node. Each node is resolved before its children, since resolving
+// it may expand it, but siblings and their subtrees are resolved concurrently by a
+// pool of ln.jobs workers. Nodes are skipped once ctx is done.
+func (ln *Linker) resolveAll(ctx context.Context, tr *parser.Tree) map[*parser.Node]error {
+	var (
+		errs    = make(map[*parser.Node]error)
+		mu      sync.Mutex
+		resolve = func(n *parser.Node) {
+			err := ln.resolve(n)
+			if err != nil {
+				mu.Lock()
+				errs[n] = err
+				mu.Unlock()
+			}
+			if ln.obs != nil && (err == nil || isConflict(err)) {
+				ln.obs.NodeResolved(n)
+			}
+		}
+	)
+	if ln.jobs <= 1 {
+		// Walking the tree also keeps the observer notified in order.
+		tr.Walk(func(n *parser.Node) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			resolve(n)
+			return nil
+		})
+		return errs
+	}
+	var (
+		wg    sync.WaitGroup
+		nodes = make(chan *parser.Node)
+		done  = make(chan *parser.Node)
+	)
+	wg.Add(ln.jobs)
+	for i := 0; i < ln.jobs; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range nodes {
+				resolve(n)
+				done <- n
+			}
+		}()
+	}
+	// Nodes are queued here instead of by workers, so
+	// that workers never block each other when queueing.
+	var (
+		queue    = append([]*parser.Node(nil), tr.Root.Children...)
+		pending  int
+		canceled = ctx.Done()
+	)
+	for len(queue) > 0 || pending > 0 {
+		var (
+			next chan<- *parser.Node
+			n    *parser.Node
+		)
+		if len(queue) > 0 {
+			next, n = nodes, queue[0]
+		}
+		select {
+		case next <- n:
+			queue = queue[1:]
+			pending++
+		case n := <-done:
+			queue = append(queue, n.Children...)
+			pending--
+		case <-canceled:
+			canceled = nil
+		}
+		if ctx.Err() != nil {
+			// Wait for nodes being resolved, but don't send any others.
+			queue = nil
+		}
+	}
+	close(nodes)
+	wg.Wait()
+	return errs
+}
+
 func (ln *Linker) resolve(n *parser.Node) error {
 	tgpath := n.Target.FullPath()
 	target, err := ln.fs.Stat(tgpath)
@@ -99,7 +250,7 @@ func (ln *Linker) resolve(n *parser.Node) error {
 	}
 	if !target.Exists() {
 		n.Status = parser.StatusError
-		return errWithPath(tgpath, ErrTargetNotExist)
+		return &NodeError{Node: n, Path: tgpath, Err: ErrTargetNotExist}
 	}
 	if len(n.Children) > 0 || len(n.Link.Path) == 0 {
 		n.Status = parser.StatusSkip
@@ -120,15 +271,15 @@ func (ln *Linker) resolve(n *parser.Node) error {
 			return nil
 		}
 		n.Status = parser.StatusConflict
-		return errWithPath(lnpath, ErrLinkExist)
+		return &NodeError{Node: n, Path: lnpath, Linkname: linkname, Err: ErrLinkExist}
 	}
 	if !target.IsDir() {
 		n.Status = parser.StatusConflict
-		return errWithPath(tgpath, ErrTargetNotExpand)
+		return &NodeError{Node: n, Path: tgpath, Err: ErrTargetNotExpand}
 	}
 	if !link.IsDir() {
 		n.Status = parser.StatusConflict
-		return errWithPath(lnpath, ErrLinkNotExpand)
+		return &NodeError{Node: n, Path: lnpath, Err: ErrLinkNotExpand}
 	}
 	children, err := ln.fs.ReadDir(tgpath)
 	if err != nil {
@@ -139,6 +290,23 @@ func (ln *Linker) resolve(n *parser.Node) error {
 	return nil
 }
 
+// isConflict reports whether err is a conflict, rather than an error that aborts
+// resolving a tree.
+func isConflict(err error) bool {
+	switch {
+	case errors.Is(err, ErrLinkExist):
+		fallthrough
+	case errors.Is(err, ErrLinkNotExpand):
+		fallthrough
+	case errors.Is(err, ErrTargetNotExist):
+		fallthrough
+	case errors.Is(err, ErrTargetNotExpand):
+		return true
+	default:
+		return false
+	}
+}
+
 func expand(n *parser.Node, children []fs.FileInfo) {
 	if len(children) <= 0 {
 		return
@@ -160,5 +328,3 @@ func expand(n *parser.Node, children []fs.FileInfo) {
 		}
 	}
 }
-
-func errWithPath(path string, err error) error { return fmt.Errorf("linker: %s: %w", path, err) }
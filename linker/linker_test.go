@@ -1,8 +1,12 @@
 package linker_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/gbrlsnchs/pilgo/fs"
@@ -10,11 +14,16 @@ import (
 	"github.com/gbrlsnchs/pilgo/linker"
 	"github.com/gbrlsnchs/pilgo/parser"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestLinker(t *testing.T) {
 	t.Run("Link", testLink)
 	t.Run("Resolve", testResolve)
+	t.Run("Jobs", testJobs)
+	t.Run("Context", testContext)
+	t.Run("Observer", testObserver)
+	t.Run("NodeError", testNodeError)
 }
 
 func testLink(t *testing.T) {
@@ -1076,3 +1085,346 @@ func testResolve(t *testing.T) {
 		})
 	}
 }
+
+func testJobs(t *testing.T) {
+	// newTree returns a tree with nodes of every status and a driver with files for it.
+	newTree := func() (*parser.Tree, *fstest.InMemoryDriver) {
+		var (
+			dotfiles = make(map[string]fstest.File)
+			home     = make(map[string]fstest.File)
+			tr       = &parser.Tree{Root: new(parser.Node)}
+		)
+		newNode := func(path ...string) *parser.Node {
+			return &parser.Node{
+				Target: parser.File{BaseDir: "dotfiles", Path: path},
+				Link:   parser.File{BaseDir: "home", Path: path},
+			}
+		}
+		for i := 0; i < 600; i++ {
+			name := fmt.Sprintf("file%03d", i)
+			n := newNode(name)
+			switch i % 6 {
+			case 0: // ready
+				dotfiles[name] = fstest.File{}
+			case 1: // done
+				dotfiles[name] = fstest.File{}
+				home[name] = fstest.File{Linkname: filepath.Join("dotfiles", name)}
+			case 2: // target not expandable
+				dotfiles[name] = fstest.File{}
+				home[name] = fstest.File{}
+			case 3: // target doesn't exist
+			case 4: // expanded, with a conflict and a ready child
+				dotfiles[name] = fstest.File{Children: map[string]fstest.File{
+					"bar": {},
+					"foo": {},
+				}}
+				home[name] = fstest.File{Children: map[string]fstest.File{
+					"foo": {Linkname: "foo"},
+				}}
+			case 5: // skipped, with a child in a subdirectory
+				dotfiles[name] = fstest.File{Children: map[string]fstest.File{
+					"foo": {},
+				}}
+				n.Children = []*parser.Node{newNode(name, "foo")}
+			}
+			tr.Root.Children = append(tr.Root.Children, n)
+		}
+		return tr, &fstest.InMemoryDriver{Files: map[string]fstest.File{
+			"dotfiles": {Children: dotfiles},
+			"home":     {Children: home},
+		}}
+	}
+	resolve := func(opts ...linker.Option) (*parser.Tree, []string) {
+		tr, drv := newTree()
+		err := linker.New(fs.New(drv), opts...).Resolve(tr)
+		var cft *linker.ConflictError
+		if !errors.As(err, &cft) {
+			t.Fatalf("want %T, got %v", cft, err)
+		}
+		conflicts := make([]string, len(cft.Errs))
+		for i, err := range cft.Errs {
+			conflicts[i] = err.Error()
+		}
+		return tr, conflicts
+	}
+
+	want, wantConflicts := resolve()
+	if want, got := 300, len(wantConflicts); got != want {
+		t.Fatalf("want %d conflicts, got %d", want, got)
+	}
+	for _, jobs := range []int{0, 2, 8, 64} {
+		t.Run(fmt.Sprint(jobs), func(t *testing.T) {
+			got, gotConflicts := resolve(linker.Jobs(jobs))
+			if !cmp.Equal(got, want) {
+				t.Errorf("(*Linker).Resolve mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+			if !cmp.Equal(gotConflicts, wantConflicts) {
+				t.Errorf("ConflictError.Errs mismatch (-want +got):\n%s", cmp.Diff(wantConflicts, gotConflicts))
+			}
+		})
+	}
+	t.Run("limit", func(t *testing.T) {
+		tr, drv := newTree()
+		cdrv := &countDriver{InMemoryDriver: drv}
+		linker.New(fs.New(cdrv), linker.Jobs(4)).Resolve(tr)
+		if want, got := 4, cdrv.max; got > want {
+			t.Fatalf("want at most %d concurrent calls, got %d", want, got)
+		}
+	})
+}
+
+// countDriver counts the maximum number of concurrent calls to Stat.
+type countDriver struct {
+	*fstest.InMemoryDriver
+	mu     sync.Mutex
+	active int
+	max    int
+}
+
+func (drv *countDriver) Stat(filename string) (fs.FileInfo, error) {
+	drv.mu.Lock()
+	if drv.active++; drv.active > drv.max {
+		drv.max = drv.active
+	}
+	drv.mu.Unlock()
+	defer func() {
+		drv.mu.Lock()
+		drv.active--
+		drv.mu.Unlock()
+	}()
+	return drv.InMemoryDriver.Stat(filename)
+}
+
+// cancelDriver cancels a context as soon as the first symlink is created.
+type cancelDriver struct {
+	*fstest.InMemoryDriver
+	cancel context.CancelFunc
+}
+
+func (drv cancelDriver) Symlink(oldname, newname string) error {
+	defer drv.cancel()
+	return drv.InMemoryDriver.Symlink(oldname, newname)
+}
+
+func testContext(t *testing.T) {
+	newTree := func() *parser.Tree {
+		var children []*parser.Node
+		for _, name := range []string{"bar", "baz", "foo"} {
+			children = append(children, &parser.Node{
+				Target: parser.File{BaseDir: "dotfiles", Path: []string{name}},
+				Link:   parser.File{BaseDir: "home", Path: []string{name}},
+			})
+		}
+		return &parser.Tree{Root: &parser.Node{Children: children}}
+	}
+	newDriver := func() *fstest.InMemoryDriver {
+		return &fstest.InMemoryDriver{Files: map[string]fstest.File{
+			"dotfiles": {Children: map[string]fstest.File{
+				"bar": {},
+				"baz": {},
+				"foo": {},
+			}},
+			"home": {Children: map[string]fstest.File{}},
+		}}
+	}
+	t.Run("Resolve", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		tr := newTree()
+		err := linker.New(fs.New(newDriver()), linker.Jobs(2)).ResolveContext(ctx, tr)
+		if want, got := context.Canceled, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		for _, n := range tr.Root.Children {
+			if want, got := parser.Status(0), n.Status; got != want {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+		}
+	})
+	t.Run("Link", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		drv := newDriver()
+		ln := linker.New(fs.New(cancelDriver{drv, cancel}))
+		err := ln.LinkContext(ctx, newTree())
+		if want, got := context.Canceled, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		want := map[string]fstest.File{
+			"bar": {Linkname: filepath.Join("dotfiles", "bar"), Perm: os.ModePerm},
+		}
+		if got := drv.Files["home"].Children; !cmp.Equal(got, want) {
+			t.Fatalf("(*Linker).LinkContext mismatch (-want +got):\n%s", cmp.Diff(want, got))
+		}
+	})
+}
+
+// recordObserver records events as strings.
+type recordObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (obs *recordObserver) NodeResolved(n *parser.Node) {
+	obs.record("resolved %s: %v", n.Target.FullPath(), n.Status)
+}
+
+func (obs *recordObserver) ConflictFound(n *parser.Node, err error) {
+	obs.record("conflict %s: %v", n.Target.FullPath(), err)
+}
+
+func (obs *recordObserver) DirCreated(dirname string) { obs.record("mkdir %s", dirname) }
+
+func (obs *recordObserver) SymlinkCreated(oldname, newname string) {
+	obs.record("symlink %s -> %s", newname, oldname)
+}
+
+func (obs *recordObserver) record(format string, args ...interface{}) {
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	obs.events = append(obs.events, fmt.Sprintf(format, args...))
+}
+
+func testObserver(t *testing.T) {
+	var (
+		parent = filepath.Join("home", "config")
+		config = filepath.Join(parent, "nvim")
+	)
+	newTree := func(names ...string) *parser.Tree {
+		var children []*parser.Node
+		for _, name := range names {
+			children = append(children, &parser.Node{
+				Target: parser.File{BaseDir: "dotfiles", Path: []string{name}},
+				Link:   parser.File{BaseDir: config, Path: []string{name}},
+			})
+		}
+		return &parser.Tree{Root: &parser.Node{Children: children}}
+	}
+	newDriver := func() *fstest.InMemoryDriver {
+		return &fstest.InMemoryDriver{Files: map[string]fstest.File{
+			"dotfiles": {Children: map[string]fstest.File{
+				"bar": {},
+				"foo": {},
+			}},
+			"home": {Children: map[string]fstest.File{}},
+		}}
+	}
+	var (
+		bar = filepath.Join("dotfiles", "bar")
+		baz = filepath.Join("dotfiles", "baz")
+		foo = filepath.Join("dotfiles", "foo")
+	)
+	testCases := []struct {
+		name      string
+		tr        *parser.Tree
+		events    []string
+		conflicts int
+	}{
+		{
+			name: "link",
+			tr:   newTree("bar", "foo"),
+			events: []string{
+				fmt.Sprintf("resolved %s: READY", bar),
+				fmt.Sprintf("resolved %s: READY", foo),
+				fmt.Sprintf("mkdir %s", parent),
+				fmt.Sprintf("mkdir %s", config),
+				fmt.Sprintf("symlink %s -> %s", filepath.Join(config, "bar"), bar),
+				fmt.Sprintf("symlink %s -> %s", filepath.Join(config, "foo"), foo),
+			},
+			conflicts: 0,
+		},
+		{
+			name: "conflict",
+			tr:   newTree("bar", "baz", "foo"),
+			events: []string{
+				fmt.Sprintf("resolved %s: READY", bar),
+				fmt.Sprintf("resolved %s: ERROR", baz),
+				fmt.Sprintf("resolved %s: READY", foo),
+				fmt.Sprintf("conflict %s: linker: %s: %v", baz, baz, linker.ErrTargetNotExist),
+			},
+			conflicts: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				obs recordObserver
+				ln  = linker.New(fs.New(newDriver()), linker.WithObserver(&obs))
+				err = ln.Link(tc.tr)
+				cft *linker.ConflictError
+			)
+			var conflicts int
+			if err != nil {
+				if !errors.As(err, &cft) {
+					t.Fatal(err)
+				}
+				conflicts = len(cft.Errs)
+			}
+			if want, got := tc.conflicts, conflicts; got != want {
+				t.Fatalf("want %d conflicts, got %d", want, got)
+			}
+			if want, got := tc.events, obs.events; !cmp.Equal(got, want) {
+				t.Fatalf("Observer events mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testNodeError(t *testing.T) {
+	drv := &fstest.InMemoryDriver{Files: map[string]fstest.File{
+		"dotfiles": {Children: map[string]fstest.File{
+			"bar": {},
+			"baz": {Children: map[string]fstest.File{}},
+			"foo": {},
+		}},
+		"home": {Children: map[string]fstest.File{
+			"bar": {Linkname: "bar"},
+			"baz": {},
+		}},
+	}}
+	var nodes []*parser.Node
+	for _, name := range []string{"bar", "baz", "foo", "qux"} {
+		nodes = append(nodes, &parser.Node{
+			Target: parser.File{BaseDir: "dotfiles", Path: []string{name}},
+			Link:   parser.File{BaseDir: "home", Path: []string{name}},
+		})
+	}
+	tr := &parser.Tree{Root: &parser.Node{Children: nodes}}
+	err := linker.New(fs.New(drv)).Resolve(tr)
+	var cft *linker.ConflictError
+	if !errors.As(err, &cft) {
+		t.Fatalf("want %T, got %v", cft, err)
+	}
+	want := []linker.NodeError{
+		{
+			Node:     nodes[0],
+			Path:     filepath.Join("home", "bar"),
+			Linkname: "bar",
+			Err:      linker.ErrLinkExist,
+		},
+		{
+			Node: nodes[1],
+			Path: filepath.Join("home", "baz"),
+			Err:  linker.ErrLinkNotExpand,
+		},
+		{
+			Node: nodes[3],
+			Path: filepath.Join("dotfiles", "qux"),
+			Err:  linker.ErrTargetNotExist,
+		},
+	}
+	var got []linker.NodeError
+	for _, err := range cft.Errs {
+		var nerr *linker.NodeError
+		if !errors.As(err, &nerr) {
+			t.Fatalf("want %T, got %v", nerr, err)
+		}
+		if !errors.Is(err, nerr.Err) {
+			t.Fatalf("want %v to match %v", err, nerr.Err)
+		}
+		got = append(got, *nerr)
+	}
+	if !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+		t.Fatalf("ConflictError.Errs mismatch (-want +got):\n%s", cmp.Diff(want, got, cmpopts.EquateErrors()))
+	}
+}
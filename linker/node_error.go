@@ -0,0 +1,22 @@
+package linker
+
+import (
+	"fmt"
+
+	"github.com/gbrlsnchs/pilgo/parser"
+)
+
+// NodeError is a conflict found while resolving a node. It matches its sentinel
+// error, like ErrLinkExist, with errors.Is.
+type NodeError struct {
+	Node *parser.Node
+	// Path is the offending path, which is either the node's target or its link.
+	Path string
+	// Linkname is what the existing link points to, if there is one.
+	Linkname string
+	Err      error
+}
+
+func (e *NodeError) Error() string { return fmt.Sprintf("linker: %s: %v", e.Path, e.Err) }
+
+func (e *NodeError) Unwrap() error { return e.Err }
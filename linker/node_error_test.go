@@ -0,0 +1,26 @@
+package linker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/linker"
+)
+
+func TestNodeError(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		err := &linker.NodeError{Path: "foo", Err: linker.ErrLinkExist}
+		if want, got := "linker: foo: file exists in place of link", err.Error(); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	})
+	t.Run("Unwrap", func(t *testing.T) {
+		var err error = &linker.NodeError{Path: "foo", Err: linker.ErrTargetNotExist}
+		if want, got := linker.ErrTargetNotExist, err; !errors.Is(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		if errors.Is(err, linker.ErrLinkExist) {
+			t.Fatalf("want %v not to match %v", err, linker.ErrLinkExist)
+		}
+	})
+}
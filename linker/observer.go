@@ -0,0 +1,24 @@
+package linker
+
+import "github.com/gbrlsnchs/pilgo/parser"
+
+// Observer is notified of what a Linker does, as it happens. If nodes are resolved
+// by more than one job, its methods may be called concurrently.
+type Observer interface {
+	// NodeResolved is called after n is resolved, with its status already set.
+	NodeResolved(n *parser.Node)
+	// ConflictFound is called for every conflict, in the order they're reported.
+	ConflictFound(n *parser.Node, err error)
+	// DirCreated is called for every directory created as the parent of a link,
+	// or as one of its parents, after it's created.
+	DirCreated(dirname string)
+	// SymlinkCreated is called after newname is created as a symlink to oldname.
+	SymlinkCreated(oldname, newname string)
+}
+
+// WithObserver sets an observer to be notified of what the linker does.
+func WithObserver(obs Observer) Option {
+	return func(ln *Linker) {
+		ln.obs = obs
+	}
+}
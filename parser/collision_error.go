@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Collision is a pair of targets whose links collide. Either both
+// links are the same or Target's link is nested inside Other's link.
+type Collision struct {
+	Target *Node
+	Other  *Node
+}
+
+func (c *Collision) Error() string {
+	tg, other := filepath.Join(c.Target.Target.Path...), filepath.Join(c.Other.Target.Path...)
+	lnpath := c.Target.Link.FullPath()
+	if c.Nested() {
+		return fmt.Sprintf("parser: %s is linked inside link of %s: %s", tg, other, lnpath)
+	}
+	return fmt.Sprintf("parser: %s and %s are linked to the same file: %s", tg, other, lnpath)
+}
+
+// Nested returns whether Target's link is nested inside Other's link.
+func (c *Collision) Nested() bool { return c.Target.Link.FullPath() != c.Other.Link.FullPath() }
+
+// CollisionError represents a group of link collisions.
+type CollisionError struct {
+	Collisions []*Collision
+}
+
+func (e *CollisionError) Error() string {
+	n := len(e.Collisions)
+	verb, collisions := "are", "link collisions"
+	if n == 1 {
+		verb, collisions = "is", "link collision"
+	}
+	return fmt.Sprintf("parser: there %s %d %s", verb, n, collisions)
+}
+
+func detectCollisions(tr *Tree) error {
+	var (
+		links = make(map[string]*Node)
+		nodes []*Node
+	)
+	tr.Walk(func(n *Node) error {
+		// Only leaves are linked.
+		if len(n.Children) > 0 || len(n.Link.Path) == 0 {
+			return nil
+		}
+		nodes = append(nodes, n)
+		return nil
+	})
+	cerr := new(CollisionError)
+	for _, n := range nodes {
+		lnpath := n.Link.FullPath()
+		if other, ok := links[lnpath]; ok {
+			cerr.Collisions = append(cerr.Collisions, &Collision{Target: n, Other: other})
+			continue
+		}
+		links[lnpath] = n
+	}
+	for _, n := range nodes {
+		lnpath := n.Link.FullPath()
+		for dir := filepath.Dir(lnpath); dir != lnpath; dir, lnpath = filepath.Dir(dir), dir {
+			if other, ok := links[dir]; ok {
+				cerr.Collisions = append(cerr.Collisions, &Collision{Target: n, Other: other})
+				break
+			}
+		}
+	}
+	if len(cerr.Collisions) > 0 {
+		return cerr
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gbrlsnchs/pilgo/parser"
+)
+
+func TestCollision(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		testCases := []struct {
+			c    *parser.Collision
+			want string
+		}{
+			{
+				&parser.Collision{
+					Target: &parser.Node{
+						Target: parser.File{"", []string{"foo"}},
+						Link:   parser.File{"test", []string{"foo"}},
+					},
+					Other: &parser.Node{
+						Target: parser.File{"", []string{"bar"}},
+						Link:   parser.File{"test", []string{"foo"}},
+					},
+				},
+				"parser: foo and bar are linked to the same file: " + filepath.Join("test", "foo"),
+			},
+			{
+				&parser.Collision{
+					Target: &parser.Node{
+						Target: parser.File{"", []string{"foo", "bar"}},
+						Link:   parser.File{"test", []string{"baz", "bar"}},
+					},
+					Other: &parser.Node{
+						Target: parser.File{"", []string{"baz"}},
+						Link:   parser.File{"test", []string{"baz"}},
+					},
+				},
+				"parser: " + filepath.Join("foo", "bar") + " is linked inside link of baz: " + filepath.Join("test", "baz", "bar"),
+			},
+		}
+		for _, tc := range testCases {
+			t.Run("", func(t *testing.T) {
+				errm := tc.c.Error()
+				if want, got := tc.want, errm; got != want {
+					t.Fatalf("want %q, got %q", want, got)
+				}
+			})
+		}
+	})
+}
+
+func TestCollisionError(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		testCases := []struct {
+			cerr *parser.CollisionError
+			want string
+		}{
+			{new(parser.CollisionError), "parser: there are 0 link collisions"},
+			{&parser.CollisionError{Collisions: make([]*parser.Collision, 1)}, "parser: there is 1 link collision"},
+			{&parser.CollisionError{Collisions: make([]*parser.Collision, 2)}, "parser: there are 2 link collisions"},
+		}
+		for _, tc := range testCases {
+			t.Run("", func(t *testing.T) {
+				errm := tc.cerr.Error()
+				if want, got := tc.want, errm; got != want {
+					t.Fatalf("want %q, got %q", want, got)
+				}
+			})
+		}
+	})
+}
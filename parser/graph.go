@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// graphColors are the colors of nodes and edges for each status in graphs.
+var graphColors = map[Status]string{
+	StatusDone:     "#228b22",
+	StatusReady:    "#daa520",
+	StatusConflict: "#dc143c",
+	StatusError:    "#dc143c",
+}
+
+// graphStatuses are the statuses that have a color in graphs, in order.
+var graphStatuses = []Status{StatusDone, StatusReady, StatusConflict, StatusError}
+
+var (
+	dotEscaper     = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	mermaidEscaper = strings.NewReplacer(`"`, "#quot;")
+)
+
+// graph is a tree flattened into base directory clusters,
+// with edges from targets to their links.
+type graph struct {
+	clusters []*cluster
+	edges    []*edge
+}
+
+type cluster struct {
+	label string
+	nodes []*graphNode
+}
+
+type graphNode struct {
+	id     string
+	label  string
+	status Status
+}
+
+type edge struct {
+	from, to *graphNode
+	status   Status
+}
+
+func (tr *Tree) graph(p *printer) *graph {
+	var (
+		g        graph
+		clusters = make(map[string]*cluster)
+		nedges   int
+	)
+	add := func(f File, confDir, id string, st Status) *graphNode {
+		c, ok := clusters[f.BaseDir]
+		if !ok {
+			c = &cluster{label: p.path(f.BaseDir, confDir)}
+			clusters[f.BaseDir] = c
+			g.clusters = append(g.clusters, c)
+		}
+		gn := &graphNode{
+			id:     id,
+			label:  filepath.Join(f.Path...),
+			status: st,
+		}
+		c.nodes = append(c.nodes, gn)
+		return gn
+	}
+	tr.Walk(func(n *Node) error {
+		if !hasLink(n) {
+			return nil
+		}
+		confDir := n.Target.BaseDir
+		target := add(n.Target, confDir, fmt.Sprintf("t%d", nedges), n.Status)
+		link := add(n.Link, confDir, fmt.Sprintf("l%d", nedges), 0)
+		nedges++
+		g.edges = append(g.edges, &edge{target, link, n.Status})
+		return nil
+	})
+	return &g
+}
+
+// WriteDot writes tr to w as a Graphviz graph, from targets to their links,
+// grouped in clusters by base directory. When tr is resolved, targets
+// and edges are colored by status. Only path options are used.
+func (tr *Tree) WriteDot(w io.Writer, opts ...PrintOption) error {
+	var (
+		g  = tr.graph(newPrinter(opts))
+		bw = bufio.NewWriter(w)
+	)
+	fmt.Fprintln(bw, "digraph pilgo {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	for i, c := range g.clusters {
+		fmt.Fprintf(bw, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(bw, "\t\tlabel=\"%s\";\n", dotEscaper.Replace(c.label))
+		for _, gn := range c.nodes {
+			fmt.Fprintf(bw, "\t\t%s [%s];\n", gn.id, dotAttrs(gn.label, gn.status))
+		}
+		fmt.Fprintln(bw, "\t}")
+	}
+	for _, e := range g.edges {
+		if attrs := dotAttrs("", e.status); attrs != "" {
+			fmt.Fprintf(bw, "\t%s -> %s [%s];\n", e.from.id, e.to.id, attrs)
+			continue
+		}
+		fmt.Fprintf(bw, "\t%s -> %s;\n", e.from.id, e.to.id)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotAttrs returns the attributes for label, if not empty, and for the color of s, if any.
+func dotAttrs(label string, s Status) string {
+	var attrs []string
+	if label != "" {
+		attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotEscaper.Replace(label)))
+	}
+	if color, ok := graphColors[s]; ok {
+		attrs = append(attrs, fmt.Sprintf("color=\"%s\"", color))
+	}
+	return strings.Join(attrs, ", ")
+}
+
+// WriteMermaid writes tr to w as a Mermaid flowchart, from targets to their
+// links, grouped in subgraphs by base directory. When tr is resolved,
+// targets and edges are colored by status. Only path options are used.
+func (tr *Tree) WriteMermaid(w io.Writer, opts ...PrintOption) error {
+	var (
+		g  = tr.graph(newPrinter(opts))
+		bw = bufio.NewWriter(w)
+	)
+	fmt.Fprintln(bw, "flowchart LR")
+	for i, c := range g.clusters {
+		fmt.Fprintf(bw, "\tsubgraph c%d [\"%s\"]\n", i, mermaidEscaper.Replace(c.label))
+		for _, gn := range c.nodes {
+			fmt.Fprintf(bw, "\t\t%s[\"%s\"]\n", gn.id, mermaidEscaper.Replace(gn.label))
+		}
+		fmt.Fprintln(bw, "\tend")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(bw, "\t%s --> %s\n", e.from.id, e.to.id)
+	}
+	for _, st := range graphStatuses {
+		var ids []string
+		for _, e := range g.edges {
+			if e.status == st {
+				ids = append(ids, e.from.id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		name := st.str()
+		fmt.Fprintf(bw, "\tclassDef %s stroke:%s\n", name, graphColors[st])
+		fmt.Fprintf(bw, "\tclass %s %s\n", strings.Join(ids, ","), name)
+	}
+	for i, e := range g.edges {
+		if color, ok := graphColors[e.status]; ok {
+			fmt.Fprintf(bw, "\tlinkStyle %d stroke:%s\n", i, color)
+		}
+	}
+	return bw.Flush()
+}
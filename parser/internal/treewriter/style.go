@@ -0,0 +1,22 @@
+package treewriter
+
+// Style is a set of glyphs used to draw a tree's branches.
+type Style struct {
+	// Fork precedes a node that has siblings after it.
+	Fork string
+	// Last precedes a node that is the last of its siblings.
+	Last string
+	// Pipe is drawn below a forked node, for each level of its descendants.
+	Pipe string
+	// Blank is drawn below a last node, for each level of its descendants.
+	Blank string
+}
+
+var (
+	// Unicode draws branches using box-drawing characters.
+	Unicode = Style{Fork: "├──", Last: "└──", Pipe: "│   ", Blank: "    "}
+	// ASCII draws branches using only ASCII characters.
+	ASCII = Style{Fork: "|--", Last: "`--", Pipe: "|   ", Blank: "    "}
+	// Indent draws no branches, only indenting nodes.
+	Indent = Style{Fork: " ", Last: " ", Pipe: "  ", Blank: "  "}
+)
@@ -7,13 +7,34 @@ import (
 
 // Writer is a buffer that prints a tree.
 type Writer struct {
-	w    io.Writer
-	root Node
+	w        io.Writer
+	root     Node
+	style    Style
+	maxDepth int
+}
+
+// Option is a functional option that changes how a Writer prints a tree.
+type Option func(*Writer)
+
+// UseStyle sets the glyphs used to draw branches. The default is Unicode.
+func UseStyle(s Style) Option {
+	return func(w *Writer) { w.style = s }
+}
+
+// MaxDepth limits how many levels below the root are printed. Truncated
+// nodes are followed by how many nodes are hidden below them.
+// Zero, which is the default, means no limit.
+func MaxDepth(n int) Option {
+	return func(w *Writer) { w.maxDepth = n }
 }
 
 // NewWriter builds the tree and stores it in the Writer.
-func NewWriter(w io.Writer, root Node) *Writer {
-	return &Writer{w: w, root: root}
+func NewWriter(w io.Writer, root Node, opts ...Option) *Writer {
+	tw := &Writer{w: w, root: root, style: Unicode}
+	for _, opt := range opts {
+		opt(tw)
+	}
+	return tw
 }
 
 func (w *Writer) Write(prelude []byte) (int, error) {
@@ -33,51 +54,66 @@ func (w *Writer) Write(prelude []byte) (int, error) {
 	if err != nil {
 		return total + n, err
 	}
-	if n, err := write(ww, w.root, make([]bool, 0)); err != nil {
+	if n, err := w.write(w.root, make([]bool, 0)); err != nil {
 		return total + n, err
 	}
 	total += n
 	return total, nil
 }
 
-func write(w io.Writer, n Node, lastlist []bool) (int, error) {
-	var total int
+func (w *Writer) write(n Node, lastlist []bool) (int, error) {
+	var (
+		total int
+		ww    = w.w
+		style = w.style
+	)
 	for i, isLast := range lastlist {
 		deepest := i == len(lastlist)-1
 		if deepest {
-			char := "├"
+			glyph := style.Fork
 			if isLast {
-				char = "└"
+				glyph = style.Last
 			}
-			n, err := fmt.Fprintf(w, "%s──", char)
+			n, err := fmt.Fprint(ww, glyph)
 			if err != nil {
 				return total + n, err
 			}
 			continue
 		}
-		char := "│"
+		glyph := style.Pipe
 		if isLast {
-			char = " "
+			glyph = style.Blank
 		}
-		fmt.Fprintf(w, "%s   ", char)
+		fmt.Fprint(ww, glyph)
 	}
 	nstr := fmt.Sprint(n)
 	if nstr != "" {
-		n, err := fmt.Fprintf(w, " %s", nstr)
+		n, err := fmt.Fprintf(ww, " %s", nstr)
 		if err != nil {
 			return total + n, err
 		}
 		total += n
 	}
-	nn, err := fmt.Fprintln(w)
+	nlen := n.Len()
+	truncated := w.maxDepth > 0 && len(lastlist) >= w.maxDepth
+	if truncated && nlen > 0 {
+		n, err := fmt.Fprintf(ww, " [%d hidden]", count(n))
+		if err != nil {
+			return total + n, err
+		}
+		total += n
+	}
+	nn, err := fmt.Fprintln(ww)
 	if err != nil {
 		return total + nn, err
 	}
 	total += nn
-	nlen := n.Len()
+	if truncated {
+		return total, nil
+	}
 	for i := 0; i < nlen; i++ {
 		isLast := i == nlen-1
-		n, err := write(w, n.At(i), append(lastlist, isLast))
+		n, err := w.write(n.At(i), append(lastlist, isLast))
 		if err != nil {
 			return total + n, err
 		}
@@ -85,3 +121,13 @@ func write(w io.Writer, n Node, lastlist []bool) (int, error) {
 	}
 	return total, nil
 }
+
+// count returns how many nodes there are below n.
+func count(n Node) int {
+	nlen := n.Len()
+	total := nlen
+	for i := 0; i < nlen; i++ {
+		total += count(n.At(i))
+	}
+	return total
+}
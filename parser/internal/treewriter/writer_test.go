@@ -12,6 +12,7 @@ func TestWriter(t *testing.T) {
 	testCases := []struct {
 		n     treewriter.Node
 		input []byte
+		opts  []treewriter.Option
 		want  string
 	}{
 		{
@@ -80,13 +81,125 @@ func TestWriter(t *testing.T) {
 │   └── baz
 └── qux
     └── quux
+`,
+		},
+		{
+			n: &testNode{
+				text: "",
+				nodes: []*testNode{
+					{text: "foo"},
+					{
+						text: "bar",
+						nodes: []*testNode{
+							{
+								text: "baz",
+								nodes: []*testNode{
+									{text: "qux"},
+								},
+							},
+						},
+					},
+					{text: "quux"},
+				},
+			},
+			input: nil,
+			opts:  []treewriter.Option{treewriter.UseStyle(treewriter.ASCII)},
+			want: ".\n" +
+				"|-- foo\n" +
+				"|-- bar\n" +
+				"|   `-- baz\n" +
+				"|       `-- qux\n" +
+				"`-- quux\n",
+		},
+		{
+			n: &testNode{
+				text: "",
+				nodes: []*testNode{
+					{text: "foo"},
+					{
+						text: "bar",
+						nodes: []*testNode{
+							{
+								text: "baz",
+								nodes: []*testNode{
+									{text: "qux"},
+								},
+							},
+						},
+					},
+					{text: "quux"},
+				},
+			},
+			input: nil,
+			opts:  []treewriter.Option{treewriter.UseStyle(treewriter.Indent)},
+			want: `.
+  foo
+  bar
+    baz
+      qux
+  quux
+`,
+		},
+		{
+			n: &testNode{
+				text: "",
+				nodes: []*testNode{
+					{text: "foo"},
+					{
+						text: "bar",
+						nodes: []*testNode{
+							{
+								text: "baz",
+								nodes: []*testNode{
+									{text: "qux"},
+								},
+							},
+						},
+					},
+					{text: "quux"},
+				},
+			},
+			input: nil,
+			opts:  []treewriter.Option{treewriter.MaxDepth(1)},
+			want: `.
+├── foo
+├── bar [2 hidden]
+└── quux
+`,
+		},
+		{
+			n: &testNode{
+				text: "",
+				nodes: []*testNode{
+					{text: "foo"},
+					{
+						text: "bar",
+						nodes: []*testNode{
+							{
+								text: "baz",
+								nodes: []*testNode{
+									{text: "qux"},
+								},
+							},
+						},
+					},
+					{text: "quux"},
+				},
+			},
+			input: nil,
+			opts:  []treewriter.Option{treewriter.MaxDepth(2)},
+			want: `.
+├── foo
+├── bar
+│   └── baz [1 hidden]
+└── quux
 `,
 		},
 	}
 	for _, tc := range testCases {
 		t.Run("Write", func(t *testing.T) {
 			var bd strings.Builder
-			tw := treewriter.NewWriter(&bd, tc.n)
+			tw := treewriter.NewWriter(&bd, tc.n, tc.opts...)
 			tw.Write(tc.input)
 			if want, got := tc.want, bd.String(); got != want {
 				t.Errorf("want %q, got %q", want, got)
@@ -15,17 +15,24 @@ type Node struct {
 	Link     File
 	Children []*Node
 	Status   Status
+	Tags     []string
 }
 
-type printableNode Node
+// hasLink reports whether n's link is printed.
+func hasLink(n *Node) bool { return n.Status&dullStatus == 0 && len(n.Link.Path) > 0 }
+
+type printableNode struct {
+	*Node
+	p *printer
+}
 
 // At returns a child node at index i.
-func (n *printableNode) At(i int) treewriter.Node { return (*printableNode)(n.Children[i]) }
+func (n printableNode) At(i int) treewriter.Node { return printableNode{n.Children[i], n.p} }
 
 // Len returns the number of children of n.
-func (n *printableNode) Len() int { return len(n.Children) }
+func (n printableNode) Len() int { return len(n.Children) }
 
-func (n *printableNode) String() string {
+func (n printableNode) String() string {
 	if len(n.Target.Path) == 0 {
 		return ""
 	}
@@ -33,16 +40,18 @@ func (n *printableNode) String() string {
 		bd     strings.Builder
 		symbol = "<-"
 	)
-	printLink := n.Status&dullStatus == 0 && len(n.Link.Path) > 0
+	printLink := hasLink(n.Node)
 	if !printLink {
 		symbol = ""
 	}
-	fmt.Fprintf(&bd, "%s\t%s", n.Target.base(), symbol)
+	// The arrow is painted even when empty, so that all cells
+	// in its column have the same amount of invisible characters.
+	fmt.Fprintf(&bd, "%s\t%s", n.Target.base(), n.p.paint(symbol, ansiBlue))
 	if printLink {
-		fmt.Fprintf(&bd, " %s", n.Link.FullPath())
+		fmt.Fprintf(&bd, " %s", n.p.link(n.Node))
 	}
 	if n.Status > 0 {
-		fmt.Fprintf(&bd, "\t(%s)", n.Status)
+		fmt.Fprintf(&bd, "\t%s", n.p.paint(fmt.Sprintf("(%s)", n.Status), statusColor(n.Status)))
 	}
 	return bd.String()
 }
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/gbrlsnchs/pilgo/config"
 )
@@ -16,38 +20,105 @@ const (
 	UserMode Mode = iota
 	// HomeMode refers to the home directory.
 	HomeMode
+	// DataMode refers to the XDG data directory.
+	DataMode
+	// StateMode refers to the XDG state directory.
+	StateMode
+	// CacheMode refers to the XDG cache directory.
+	CacheMode
+	// BinMode refers to the user's executables directory.
+	BinMode
 )
 
+// ErrUndefinedVar means a variable is neither set nor has a default value.
+var ErrUndefinedVar = errors.New("undefined variable")
+
+var modes = map[string]Mode{
+	config.BaseConfig: UserMode,
+	config.BaseHome:   HomeMode,
+	config.BaseData:   DataMode,
+	config.BaseState:  StateMode,
+	config.BaseCache:  CacheMode,
+	config.BaseBin:    BinMode,
+}
+
 // Parser is a configuration parser.
 type Parser struct {
 	cwd      string
 	baseDirs map[Mode]string
+	roots    map[string]string
+	vars     map[string]string
+	values   map[string]string
 	envsubst bool
 	tags     map[string]struct{}
 }
 
 // Parse parses a configuration file and returns its tree representation.
+// If links of different targets collide, it returns a *CollisionError.
 func (p *Parser) Parse(c *config.Config, opts ...ParseOption) (*Tree, error) {
+	return p.ParseContext(context.Background(), c, opts...)
+}
+
+// ParseContext is like Parse, but it stops parsing targets and returns ctx's error
+// as soon as ctx is done.
+func (p *Parser) ParseContext(ctx context.Context, c *config.Config, opts ...ParseOption) (*Tree, error) {
 	for _, opt := range opts {
 		if err := opt(p); err != nil {
 			return nil, err
 		}
 	}
-	root := &Node{Children: p.parseChildren(c, nil, nil)}
-	return &Tree{root}, nil
+	// Variables from the configuration may only reference environment variables.
+	p.values = make(map[string]string, len(c.Vars)+len(p.vars))
+	for name, value := range c.Vars {
+		value, err := expand(value, p.lookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		p.values[name] = value
+	}
+	for name, value := range p.vars {
+		p.values[name] = value
+	}
+	p.roots = make(map[string]string, len(c.Roots))
+	for name, dir := range c.Roots {
+		dir, err := p.expandVar(dir)
+		if err != nil {
+			return nil, err
+		}
+		p.roots[name] = dir
+	}
+	children, err := p.parseChildren(ctx, c, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tr := &Tree{&Node{Children: children}}
+	if err := detectCollisions(tr); err != nil {
+		return nil, err
+	}
+	return tr, nil
 }
 
-func (p *Parser) parseChildren(c *config.Config, ptargets, plinks []string) []*Node {
+func (p *Parser) parseChildren(ctx context.Context, c *config.Config, ptargets, plinks []string) ([]*Node, error) {
 	var children []*Node
 	tglen := len(c.Targets)
 	if tglen > 0 {
+		// Options are set for targets as they're written, before expanding them.
+		names := make(map[string]string, tglen)
 		for i, tg := range c.Targets {
-			c.Targets[i] = p.expandVar(tg)
+			expanded, err := p.expandVar(tg)
+			if err != nil {
+				return nil, err
+			}
+			names[expanded] = tg
+			c.Targets[i] = expanded
 		}
 		sort.Strings(c.Targets)
 		children = make([]*Node, 0, tglen)
 		for _, tg := range c.Targets {
-			cc := c.Options[tg]
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			cc := c.Options[names[tg]]
 			if cc == nil {
 				cc = new(config.Config) // use default config
 			}
@@ -66,6 +137,10 @@ func (p *Parser) parseChildren(c *config.Config, ptargets, plinks []string) []*N
 					continue
 				}
 			}
+			if cc.Base == "" && cc.UseHome == nil {
+				// Setting useHome also overrides the parent's base.
+				cc.Base = c.Base
+			}
 			if cc.UseHome == nil {
 				cc.UseHome = c.UseHome
 			}
@@ -74,21 +149,32 @@ func (p *Parser) parseChildren(c *config.Config, ptargets, plinks []string) []*N
 			}
 			tgs := append(make([]string, 0, len(ptargets)+1), ptargets...)
 			lns := append(make([]string, 0, len(plinks)+1), plinks...)
-			cc.BaseDir = p.expandVar(cc.BaseDir)
-			children = append(children, p.parseTarget(cc,
+			baseDir, err := p.expandVar(cc.BaseDir)
+			if err != nil {
+				return nil, err
+			}
+			cc.BaseDir = baseDir
+			n, err := p.parseTarget(ctx, cc,
 				append(tgs, tg),
-				append(lns, tg)))
+				append(lns, tg))
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, n)
 		}
 	}
-	return children
+	return children, nil
 }
 
-func (p *Parser) parseTarget(c *config.Config, targets, links []string) *Node {
-	n := &Node{Target: File{p.cwd, targets}}
+func (p *Parser) parseTarget(ctx context.Context, c *config.Config, targets, links []string) (*Node, error) {
+	n := &Node{Target: File{p.cwd, targets}, Tags: c.Tags}
 	lnlen := len(links)
 	if c.Link != "" {
 		// Replace last element from links. This is a link rename.
-		linkname := c.Link
+		linkname, err := p.expandVar(c.Link)
+		if err != nil {
+			return nil, err
+		}
 		links[lnlen-1] = linkname
 	}
 	if c.Flatten {
@@ -97,28 +183,98 @@ func (p *Parser) parseTarget(c *config.Config, targets, links []string) *Node {
 		// same underlying array between children.
 		links = append(make([]string, 0, len(s)), s...)
 	}
-	if c.BaseDir == "" {
-		mode := UserMode
+	baseDir := c.BaseDir
+	if baseDir == "" {
+		dir, err := p.resolveBase(c)
+		if err != nil {
+			return nil, err
+		}
+		baseDir = dir
+	}
+	n.Link = File{baseDir, links}
+	children, err := p.parseChildren(ctx, c, targets, links)
+	if err != nil {
+		return nil, err
+	}
+	n.Children = children
+	return n, nil
+}
+
+// resolveBase returns the base directory for c's base. If c has no base,
+// useHome decides between the home and the user configuration directories.
+func (p *Parser) resolveBase(c *config.Config) (string, error) {
+	base := c.Base
+	if base == "" {
+		base = config.BaseConfig
 		if c.UseHome != nil && *c.UseHome {
-			mode = HomeMode
+			base = config.BaseHome
 		}
-		c.BaseDir = p.baseDirs[mode]
 	}
-	n.Link = File{c.BaseDir, links}
-	n.Children = p.parseChildren(c, targets, links)
-	return n
+	if mode, ok := modes[base]; ok {
+		return p.baseDirs[mode], nil
+	}
+	if dir, ok := p.roots[base]; ok {
+		return dir, nil
+	}
+	return "", fmt.Errorf("parser: %w %q", config.ErrUnknownBase, base)
+}
+
+// expandVar expands variables in s. Paths are kept as they're written when there are
+// no variables to expand and environment variables aren't enabled either.
+func (p *Parser) expandVar(s string) (string, error) {
+	if !p.envsubst && len(p.values) == 0 {
+		return s, nil
+	}
+	return expand(s, p.lookupVar)
+}
+
+func (p *Parser) lookupVar(name string) (string, bool) {
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+	return p.lookupEnv(name)
 }
 
-func (p *Parser) expandVar(s string) string {
+func (p *Parser) lookupEnv(name string) (string, bool) {
 	if p.envsubst {
-		return os.ExpandEnv(s)
+		return os.LookupEnv(name)
 	}
-	return s
+	return "", false
+}
+
+// expand replaces ${var} or $var in s using lookup. A default value can be set
+// with ${var:-default}, which is used when var is either unset or empty. In order
+// to have a literal dollar sign, it must be escaped as $$.
+func expand(s string, lookup func(string) (string, bool)) (string, error) {
+	var err error
+	s = os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		name, def := name, ""
+		i := strings.Index(name, ":-")
+		if i >= 0 {
+			name, def = name[:i], name[i+2:]
+		}
+		value, ok := lookup(name)
+		if i >= 0 && value == "" {
+			return def
+		}
+		if !ok && err == nil {
+			err = fmt.Errorf("parser: %w %q", ErrUndefinedVar, name)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return s, nil
 }
 
 // ParseOption is a funcional option that intend to modify a Parser.
 type ParseOption func(*Parser) error
 
+// BaseDirs sets the base directory for each mode.
 func BaseDirs(dirs map[Mode]string) ParseOption {
 	return func(p *Parser) error {
 		p.baseDirs = dirs
@@ -134,12 +290,22 @@ func Cwd(dirname string) ParseOption {
 	}
 }
 
-// Envsubst enables replacing ${var} or $var with their environment values.
+// Envsubst enables replacing ${var} or $var with their environment values
+// when they're not set as variables.
 func Envsubst(p *Parser) error {
 	p.envsubst = true
 	return nil
 }
 
+// Vars sets variables to be expanded in targets, links and base directories.
+// They take precedence over variables set in the configuration.
+func Vars(vars map[string]string) ParseOption {
+	return func(p *Parser) error {
+		p.vars = vars
+		return nil
+	}
+}
+
 // Tags filters targets by their tags.
 func Tags(tags map[string]struct{}) ParseOption {
 	return func(p *Parser) error {
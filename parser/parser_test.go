@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
@@ -13,6 +14,21 @@ import (
 
 func TestParser(t *testing.T) {
 	t.Run("Parse", testParserParse)
+	t.Run("ParseCollisions", testParserParseCollisions)
+	t.Run("ParseContext", testParserParseContext)
+}
+
+func testParserParseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var p parser.Parser
+	tr, err := p.ParseContext(ctx, &config.Config{Targets: []string{"foo", "bar"}})
+	if want, got := context.Canceled, err; !errors.Is(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if tr != nil {
+		t.Fatalf("want nil tree, got %v", tr)
+	}
 }
 
 func testParserParse(t *testing.T) {
@@ -544,6 +560,7 @@ func testParserParse(t *testing.T) {
 					{
 						Target: parser.File{"", []string{"bar"}},
 						Link:   parser.File{"test", []string{"bar"}},
+						Tags:   []string{"test"},
 					},
 					{
 						Target: parser.File{"", []string{"foo"}},
@@ -575,6 +592,7 @@ func testParserParse(t *testing.T) {
 					{
 						Target: parser.File{"", []string{"bar"}},
 						Link:   parser.File{"test", []string{"bar"}},
+						Tags:   []string{"test", "test_tag"},
 					},
 					{
 						Target: parser.File{"", []string{"foo"}},
@@ -587,6 +605,208 @@ func testParserParse(t *testing.T) {
 			})},
 			err: nil,
 		},
+		{
+			c: config.Config{
+				Base: config.BaseData,
+				Targets: []string{
+					"foo",
+					"bar",
+					"baz",
+					"qux",
+				},
+				Options: map[string]*config.Config{
+					"bar": {Base: config.BaseBin},
+					"baz": {Base: "scripts"},
+					"qux": {
+						UseHome: internal.NewBool(true),
+						Targets: []string{"test"},
+						Options: map[string]*config.Config{
+							"test": {Base: config.BaseState},
+						},
+					},
+				},
+				Roots: map[string]string{"scripts": "$MY_ENV_VAR/scripts"},
+			},
+			opts: []parser.ParseOption{
+				parser.BaseDirs(map[parser.Mode]string{
+					parser.UserMode:  "user",
+					parser.HomeMode:  "home",
+					parser.DataMode:  "data",
+					parser.StateMode: "state",
+					parser.CacheMode: "cache",
+					parser.BinMode:   "bin",
+				}),
+				parser.Envsubst,
+			},
+			tr: &parser.Tree{
+				Root: &parser.Node{Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"bar"}},
+						Link:   parser.File{"bin", []string{"bar"}},
+					},
+					{
+						Target: parser.File{"", []string{"baz"}},
+						Link:   parser.File{"home/scripts", []string{"baz"}},
+					},
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Link:   parser.File{"data", []string{"foo"}},
+					},
+					{
+						Target: parser.File{"", []string{"qux"}},
+						Link:   parser.File{"home", []string{"qux"}},
+						Children: []*parser.Node{
+							{
+								Target: parser.File{"", []string{"qux", "test"}},
+								Link:   parser.File{"state", []string{"qux", "test"}},
+							},
+						},
+					},
+				}},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Base:    config.BaseData,
+				UseHome: internal.NewBool(true),
+				Targets: []string{
+					"foo",
+					"bar",
+					"qux",
+				},
+				Options: map[string]*config.Config{
+					"foo": {UseHome: internal.NewBool(false)},
+					"bar": {
+						BaseDir: "test",
+						Targets: []string{"baz"},
+						Options: map[string]*config.Config{
+							"baz": {Base: config.BaseCache},
+						},
+					},
+				},
+			},
+			opts: []parser.ParseOption{
+				parser.BaseDirs(map[parser.Mode]string{
+					parser.UserMode:  "user",
+					parser.HomeMode:  "home",
+					parser.DataMode:  "data",
+					parser.CacheMode: "cache",
+				}),
+			},
+			tr: &parser.Tree{
+				Root: &parser.Node{Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"bar"}},
+						Link:   parser.File{"test", []string{"bar"}},
+						Children: []*parser.Node{
+							{
+								Target: parser.File{"", []string{"bar", "baz"}},
+								Link:   parser.File{"test", []string{"bar", "baz"}},
+							},
+						},
+					},
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Link:   parser.File{"user", []string{"foo"}},
+					},
+					{
+						Target: parser.File{"", []string{"qux"}},
+						Link:   parser.File{"data", []string{"qux"}},
+					},
+				}},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				Base:    "scripts",
+				Targets: []string{"foo"},
+			},
+			tr:  nil,
+			err: config.ErrUnknownBase,
+		},
+		{
+			c: config.Config{
+				BaseDir: "${base:-test}",
+				Targets: []string{
+					"${foo}",
+					"${MY_ENV_VAR}",
+					"$$bar",
+				},
+				Options: map[string]*config.Config{
+					"${foo}": {Link: "${bar}_${MY_ENV_VAR}"},
+				},
+				Vars: map[string]string{
+					"foo": "config",
+					"bar": "${MY_ENV_VAR:-nope}rc",
+				},
+			},
+			opts: []parser.ParseOption{
+				parser.Envsubst,
+				parser.Vars(map[string]string{"foo": "cli"}),
+			},
+			tr: &parser.Tree{
+				Root: &parser.Node{Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"$bar"}},
+						Link:   parser.File{"test", []string{"$bar"}},
+					},
+					{
+						Target: parser.File{"", []string{"cli"}},
+						Link:   parser.File{"test", []string{"homerc_home"}},
+					},
+					{
+						Target: parser.File{"", []string{"home"}},
+						Link:   parser.File{"test", []string{"home"}},
+					},
+				}},
+			},
+			err: nil,
+		},
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Vars:    map[string]string{"foo": "bar"},
+				Targets: []string{"$MY_ENV_VAR"},
+			},
+			tr:  nil,
+			err: parser.ErrUndefinedVar,
+		},
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo"},
+				Options: map[string]*config.Config{
+					"foo": {Link: "${bar}"},
+				},
+			},
+			opts: []parser.ParseOption{parser.Envsubst},
+			tr:   nil,
+			err:  parser.ErrUndefinedVar,
+		},
+		{
+			c: config.Config{
+				BaseDir: "$MY_ENV_VAR",
+				Targets: []string{"$foo", "$$bar"},
+				Options: map[string]*config.Config{
+					"$foo": {Link: "${baz}"},
+				},
+			},
+			tr: &parser.Tree{
+				Root: &parser.Node{Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"$$bar"}},
+						Link:   parser.File{"$MY_ENV_VAR", []string{"$$bar"}},
+					},
+					{
+						Target: parser.File{"", []string{"$foo"}},
+						Link:   parser.File{"$MY_ENV_VAR", []string{"${baz}"}},
+					},
+				}},
+			},
+			err: nil,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -604,3 +824,83 @@ func testParserParse(t *testing.T) {
 		})
 	}
 }
+
+func testParserParseCollisions(t *testing.T) {
+	testCases := []struct {
+		c          config.Config
+		collisions [][2][]string
+	}{
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo", "bar"},
+			},
+			collisions: nil,
+		},
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"bar": {Link: "foo"},
+				},
+			},
+			collisions: [][2][]string{
+				{{"foo"}, {"bar"}},
+			},
+		},
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo", "bar"},
+				Options: map[string]*config.Config{
+					"bar": {
+						Link:    "foo",
+						Targets: []string{"baz"},
+					},
+				},
+			},
+			collisions: [][2][]string{
+				{{"bar", "baz"}, {"foo"}},
+			},
+		},
+		{
+			c: config.Config{
+				BaseDir: "test",
+				Targets: []string{"foo", "bar", "qux"},
+				Options: map[string]*config.Config{
+					"bar": {
+						Flatten: true,
+						Targets: []string{"foo"},
+					},
+					"qux": {Link: "foo"},
+				},
+			},
+			collisions: [][2][]string{
+				{{"foo"}, {"bar", "foo"}},
+				{{"qux"}, {"bar", "foo"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			var p parser.Parser
+			_, err := p.Parse(&tc.c)
+			var collisions [][2][]string
+			var cerr *parser.CollisionError
+			if errors.As(err, &cerr) {
+				for _, c := range cerr.Collisions {
+					collisions = append(collisions, [2][]string{c.Target.Target.Path, c.Other.Target.Path})
+				}
+			} else if err != nil {
+				t.Fatalf("want a collision error, got %v", err)
+			}
+			if want, got := tc.collisions, collisions; !cmp.Equal(got, want) {
+				t.Fatalf(
+					"(*Parser).Parse collisions mismatch: (-want +got):\n%s",
+					cmp.Diff(want, got),
+				)
+			}
+		})
+	}
+}
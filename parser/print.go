@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gbrlsnchs/pilgo/parser/internal/treewriter"
+)
+
+// ANSI escape codes used when printing a tree with colors.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+)
+
+type printer struct {
+	colors bool
+	style  treewriter.Style
+	depth  int
+	// Only one of these is set, or none for absolute links.
+	home     string
+	relative bool
+}
+
+func newPrinter(opts []PrintOption) *printer {
+	p := &printer{style: treewriter.Unicode}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// PrintOption is a functional option that changes how a Tree is printed.
+type PrintOption func(*printer)
+
+// Colors enables ANSI colors for statuses and link arrows.
+func Colors(p *printer) { p.colors = true }
+
+// ASCIIStyle draws branches using only ASCII characters
+// instead of box-drawing characters.
+func ASCIIStyle(p *printer) { p.style = treewriter.ASCII }
+
+// IndentStyle draws no branches, only indenting nodes.
+func IndentStyle(p *printer) { p.style = treewriter.Indent }
+
+// HomePaths abbreviates home to "~" in links.
+func HomePaths(home string) PrintOption {
+	return func(p *printer) {
+		p.home = filepath.Clean(home)
+		p.relative = false
+	}
+}
+
+// RelativePaths prints links relative to the configuration directory,
+// which is the base directory of targets.
+func RelativePaths(p *printer) {
+	p.home = ""
+	p.relative = true
+}
+
+// Depth limits how many levels of targets are printed. Truncated targets
+// are followed by how many targets are hidden below them.
+func Depth(n int) PrintOption {
+	return func(p *printer) { p.depth = n }
+}
+
+// paint wraps s with color when colors are enabled. Colors don't have the
+// same length (ansiDim is one byte shorter), so cells in the same column are
+// only aligned when painted with the same color. Statuses have different colors,
+// but they're the last cell of a line, which is never padded.
+func (p *printer) paint(s, color string) string {
+	if !p.colors {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// link returns how n's link is printed.
+func (p *printer) link(n *Node) string { return p.path(n.Link.FullPath(), n.Target.BaseDir) }
+
+// path returns how path is printed, given the configuration directory.
+func (p *printer) path(path, confDir string) string {
+	switch {
+	case p.relative:
+		// Paths that can't be made relative are printed as they are.
+		if rel, err := filepath.Rel(confDir, path); err == nil {
+			return rel
+		}
+	case p.home != "":
+		if path == p.home {
+			return "~"
+		}
+		if strings.HasPrefix(path, p.home+string(filepath.Separator)) {
+			return "~" + path[len(p.home):]
+		}
+	}
+	return path
+}
+
+func statusColor(s Status) string {
+	switch s {
+	case StatusDone:
+		return ansiGreen
+	case StatusReady:
+		return ansiYellow
+	case StatusConflict, StatusError:
+		return ansiRed
+	case StatusSkip:
+		return ansiDim
+	}
+	return ansiBlue
+}
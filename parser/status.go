@@ -1,6 +1,13 @@
 package parser
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownStatus means a status name is not valid.
+var ErrUnknownStatus = errors.New("unknown status")
 
 // Status is a node's status.
 type Status uint8
@@ -25,6 +32,9 @@ const (
 
 func (s Status) String() string { return strings.ToUpper(s.str()) }
 
+// MarshalText encodes s as its lower-case name.
+func (s Status) MarshalText() ([]byte, error) { return []byte(s.str()), nil }
+
 func (s Status) str() string {
 	switch s {
 	case StatusReady:
@@ -43,3 +53,13 @@ func (s Status) str() string {
 		return "undefined"
 	}
 }
+
+// ParseStatus returns the status named s, ignoring case.
+func ParseStatus(s string) (Status, error) {
+	for st := StatusReady; st <= StatusExpand; st <<= 1 {
+		if strings.EqualFold(s, st.str()) {
+			return st, nil
+		}
+	}
+	return 0, fmt.Errorf("parser: %w %q", ErrUnknownStatus, s)
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"encoding/json"
+	"io"
 	"strings"
 	"text/tabwriter"
 
@@ -14,14 +16,117 @@ type Tree struct {
 }
 
 func (tr *Tree) String() string {
+	var bd strings.Builder
+	tr.Print(&bd)
+	return bd.String()
+}
+
+// Print writes tr to w in a tree view, the same way as String, but
+// allowing opts to change how it is printed.
+func (tr *Tree) Print(w io.Writer, opts ...PrintOption) error {
 	var (
-		bd strings.Builder
-		w  = tabwriter.NewWriter(&bd, 0, 0, 1, ' ', 0)
-		tw = treewriter.NewWriter(w, (*printableNode)(tr.Root))
+		p  = newPrinter(opts)
+		tw = tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+		rw = treewriter.NewWriter(tw, printableNode{tr.Root, p},
+			treewriter.UseStyle(p.style),
+			treewriter.MaxDepth(p.depth),
+		)
 	)
-	tw.Write(nil)
-	w.Flush()
-	return bd.String()
+	if _, err := rw.Write(nil); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// SchemaVersion is the version of the schema used by MarshalJSON and MarshalYAML.
+// It is incremented whenever a field is renamed, removed or changes meaning.
+const SchemaVersion = 1
+
+// MarshalJSON encodes tr as an object with the following fields:
+//
+//	version   schema version, which is SchemaVersion
+//	targets   list of nodes
+//
+// Each node is an object with the following fields:
+//
+//	target    full path of the target
+//	link      full path of the link, omitted if the target has no link
+//	status    lower-case status name, omitted if the tree hasn't been resolved
+//	tags      list of the target's tags, omitted if empty
+//	children  list of nested nodes, omitted if empty
+func (tr *Tree) MarshalJSON() ([]byte, error) { return json.Marshal(tr.schema()) }
+
+// MarshalYAML encodes tr using the same schema as MarshalJSON.
+func (tr *Tree) MarshalYAML() (interface{}, error) { return tr.schema(), nil }
+
+type treeSchema struct {
+	Version int           `json:"version" yaml:"version"`
+	Targets []*nodeSchema `json:"targets" yaml:"targets"`
+}
+
+type nodeSchema struct {
+	Target   string        `json:"target" yaml:"target"`
+	Link     string        `json:"link,omitempty" yaml:"link,omitempty"`
+	Status   Status        `json:"status,omitempty" yaml:"status,omitempty"`
+	Tags     []string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Children []*nodeSchema `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+func (tr *Tree) schema() *treeSchema {
+	return &treeSchema{
+		Version: SchemaVersion,
+		Targets: schemaNodes(tr.Root.Children),
+	}
+}
+
+func schemaNodes(nodes []*Node) []*nodeSchema {
+	sn := make([]*nodeSchema, 0, len(nodes))
+	for _, n := range nodes {
+		var link string
+		if len(n.Link.Path) > 0 {
+			link = n.Link.FullPath()
+		}
+		sn = append(sn, &nodeSchema{
+			Target:   n.Target.FullPath(),
+			Link:     link,
+			Status:   n.Status,
+			Tags:     n.Tags,
+			Children: schemaNodes(n.Children),
+		})
+	}
+	return sn
+}
+
+// Filter returns a copy of tr containing only nodes whose status is set in mask,
+// along with their ancestors. Nodes themselves are copied, so tr is left untouched.
+func (tr *Tree) Filter(mask Status) *Tree {
+	root := *tr.Root
+	root.Children = filter(tr.Root.Children, mask)
+	return &Tree{&root}
+}
+
+func filter(nodes []*Node, mask Status) []*Node {
+	var filtered []*Node
+	for _, n := range nodes {
+		children := filter(n.Children, mask)
+		if n.Status&mask == 0 && len(children) == 0 {
+			continue
+		}
+		cp := *n
+		cp.Children = children
+		filtered = append(filtered, &cp)
+	}
+	return filtered
+}
+
+// Count returns how many nodes have each status.
+func (tr *Tree) Count() map[Status]int {
+	count := make(map[Status]int)
+	tr.Walk(func(n *Node) error {
+		count[n.Status]++
+		return nil
+	})
+	return count
 }
 
 // Walk traverses the tree using depth-first search and runs fn for each node found.
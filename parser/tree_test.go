@@ -1,17 +1,570 @@
 package parser_test
 
 import (
+	"encoding/json"
 	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gbrlsnchs/pilgo/parser"
 	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
 )
 
 func TestTree(t *testing.T) {
+	t.Run("Count", testTreeCount)
+	t.Run("Filter", testTreeFilter)
+	t.Run("MarshalJSON", testTreeMarshalJSON)
+	t.Run("MarshalYAML", testTreeMarshalYAML)
+	t.Run("Print", testTreePrint)
+	t.Run("PrintPaths", testTreePrintPaths)
 	t.Run("String", testTreeString)
 	t.Run("Walk", testTreeWalk)
+	t.Run("WriteDot", testTreeWriteDot)
+	t.Run("WriteMermaid", testTreeWriteMermaid)
+}
+
+var marshalTree = &parser.Tree{&parser.Node{
+	Children: []*parser.Node{
+		{
+			Target: parser.File{"dotfiles", []string{"foo"}},
+			Link:   parser.File{"home", []string{"foo"}},
+			Status: parser.StatusSkip,
+			Tags:   []string{"test"},
+			Children: []*parser.Node{
+				{
+					Target: parser.File{"dotfiles", []string{"foo", "bar"}},
+					Link:   parser.File{"home", []string{"foo", "bar"}},
+					Status: parser.StatusDone,
+				},
+			},
+		},
+		{
+			Target: parser.File{"dotfiles", []string{"baz"}},
+			Link:   parser.File{"home", []string{}},
+		},
+	},
+}}
+
+func testTreeCount(t *testing.T) {
+	testCases := []struct {
+		tr   *parser.Tree
+		want map[parser.Status]int
+	}{
+		{
+			tr:   &parser.Tree{new(parser.Node)},
+			want: map[parser.Status]int{},
+		},
+		{
+			tr: marshalTree,
+			want: map[parser.Status]int{
+				0:                 1,
+				parser.StatusSkip: 1,
+				parser.StatusDone: 1,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if want, got := tc.want, tc.tr.Count(); !cmp.Equal(got, want) {
+				t.Errorf("(*Tree).Count mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testTreeFilter(t *testing.T) {
+	tr := &parser.Tree{&parser.Node{
+		Children: []*parser.Node{
+			{
+				Target: parser.File{"", []string{"foo"}},
+				Status: parser.StatusSkip,
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo", "bar"}},
+						Status: parser.StatusDone,
+					},
+					{
+						Target: parser.File{"", []string{"foo", "baz"}},
+						Status: parser.StatusConflict,
+					},
+				},
+			},
+			{
+				Target: parser.File{"", []string{"qux"}},
+				Status: parser.StatusReady,
+			},
+		},
+	}}
+	testCases := []struct {
+		mask parser.Status
+		want *parser.Tree
+	}{
+		{
+			mask: 0,
+			want: &parser.Tree{new(parser.Node)},
+		},
+		{
+			mask: parser.StatusConflict | parser.StatusReady,
+			want: &parser.Tree{&parser.Node{
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Status: parser.StatusSkip,
+						Children: []*parser.Node{
+							{
+								Target: parser.File{"", []string{"foo", "baz"}},
+								Status: parser.StatusConflict,
+							},
+						},
+					},
+					{
+						Target: parser.File{"", []string{"qux"}},
+						Status: parser.StatusReady,
+					},
+				},
+			}},
+		},
+		{
+			mask: parser.StatusSkip,
+			want: &parser.Tree{&parser.Node{
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Status: parser.StatusSkip,
+					},
+				},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if want, got := tc.want, tr.Filter(tc.mask); !cmp.Equal(got, want) {
+				t.Errorf("(*Tree).Filter mismatch (-want +got):\n%s", cmp.Diff(want, got))
+			}
+			if len(tr.Root.Children) != 2 || len(tr.Root.Children[0].Children) != 2 {
+				t.Fatal("(*Tree).Filter modified the original tree")
+			}
+		})
+	}
+}
+
+func testTreeMarshalJSON(t *testing.T) {
+	testCases := []struct {
+		tr   *parser.Tree
+		want string
+	}{
+		{
+			tr:   &parser.Tree{new(parser.Node)},
+			want: `{"version":1,"targets":[]}`,
+		},
+		{
+			tr: marshalTree,
+			want: `{"version":1,"targets":[` +
+				`{"target":"dotfiles/foo","link":"home/foo","status":"skip","tags":["test"],"children":[` +
+				`{"target":"dotfiles/foo/bar","link":"home/foo/bar","status":"done"}]},` +
+				`{"target":"dotfiles/baz"}]}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			b, err := json.Marshal(tc.tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Backslashes are escaped in JSON strings.
+			sep, _ := json.Marshal(string(filepath.Separator))
+			if want, got := strings.ReplaceAll(tc.want, "/", string(sep[1:len(sep)-1])), string(b); got != want {
+				t.Errorf("\nwant\n%s\ngot\n%s", want, got)
+			}
+		})
+	}
+}
+
+func testTreeMarshalYAML(t *testing.T) {
+	testCases := []struct {
+		tr   *parser.Tree
+		want string
+	}{
+		{
+			tr:   &parser.Tree{new(parser.Node)},
+			want: "version: 1\ntargets: []\n",
+		},
+		{
+			tr: marshalTree,
+			want: `version: 1
+targets:
+  - target: dotfiles/foo
+    link: home/foo
+    status: skip
+    tags:
+      - test
+    children:
+      - target: dotfiles/foo/bar
+        link: home/foo/bar
+        status: done
+  - target: dotfiles/baz
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			b, err := yaml.Marshal(tc.tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := filepath.FromSlash(tc.want), string(b); got != want {
+				t.Errorf("\nwant\n%s\ngot\n%s", want, got)
+			}
+		})
+	}
+}
+
+func testTreePrint(t *testing.T) {
+	tr := &parser.Tree{&parser.Node{
+		Children: []*parser.Node{
+			{
+				Target: parser.File{"", []string{"foo"}},
+				Link:   parser.File{"test", []string{"foo"}},
+				Status: parser.StatusSkip,
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo", "bar"}},
+						Link:   parser.File{"test", []string{"foo", "bar"}},
+						Status: parser.StatusDone,
+					},
+				},
+			},
+			{
+				Target: parser.File{"", []string{"baz"}},
+				Link:   parser.File{"test", []string{"baz"}},
+				Status: parser.StatusReady,
+			},
+			{
+				Target: parser.File{"", []string{"qux"}},
+				Link:   parser.File{"test", []string{"qux"}},
+				Status: parser.StatusConflict,
+			},
+		},
+	}}
+	testCases := []struct {
+		name string
+		opts []parser.PrintOption
+		want string
+	}{
+		{
+			name: "no colors",
+			opts: nil,
+			want: `.
+├── foo                     (SKIP)
+│   └── bar <- test/foo/bar (DONE)
+├── baz     <- test/baz     (READY)
+└── qux     <- test/qux     (CONFLICT)
+`,
+		},
+		{
+			name: "colors",
+			opts: []parser.PrintOption{parser.Colors},
+			want: ".\n" +
+				"├── foo     \x1b[34m\x1b[0m                \x1b[2m(SKIP)\x1b[0m\n" +
+				"│   └── bar \x1b[34m<-\x1b[0m test/foo/bar \x1b[32m(DONE)\x1b[0m\n" +
+				"├── baz     \x1b[34m<-\x1b[0m test/baz     \x1b[33m(READY)\x1b[0m\n" +
+				"└── qux     \x1b[34m<-\x1b[0m test/qux     \x1b[31m(CONFLICT)\x1b[0m\n",
+		},
+		{
+			name: "ascii style",
+			opts: []parser.PrintOption{parser.ASCIIStyle},
+			want: ".\n" +
+				"|-- foo                     (SKIP)\n" +
+				"|   `-- bar <- test/foo/bar (DONE)\n" +
+				"|-- baz     <- test/baz     (READY)\n" +
+				"`-- qux     <- test/qux     (CONFLICT)\n",
+		},
+		{
+			name: "indent style",
+			opts: []parser.PrintOption{parser.IndentStyle},
+			want: `.
+  foo                   (SKIP)
+    bar <- test/foo/bar (DONE)
+  baz   <- test/baz     (READY)
+  qux   <- test/qux     (CONFLICT)
+`,
+		},
+		{
+			name: "depth",
+			opts: []parser.PrintOption{parser.Depth(1)},
+			want: `.
+├── foo             (SKIP) [1 hidden]
+├── baz <- test/baz (READY)
+└── qux <- test/qux (CONFLICT)
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bd strings.Builder
+			if err := tr.Print(&bd, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := filepath.FromSlash(tc.want), bd.String(); got != want {
+				t.Errorf("\nwant\n%q\ngot\n%q", want, got)
+				t.Logf("\ndiff (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testTreePrintPaths(t *testing.T) {
+	var (
+		home     = filepath.FromSlash("/home/me")
+		dotfiles = filepath.Join(home, "dotfiles")
+		tr       = &parser.Tree{&parser.Node{
+			Children: []*parser.Node{
+				{
+					Target: parser.File{dotfiles, []string{"foo"}},
+					Link:   parser.File{filepath.Join(home, ".config"), []string{"foo"}},
+				},
+				{
+					Target: parser.File{dotfiles, []string{"bar"}},
+					Link:   parser.File{filepath.Dir(home), []string{"me"}},
+				},
+				{
+					Target: parser.File{dotfiles, []string{"baz"}},
+					Link:   parser.File{filepath.FromSlash("/etc"), []string{"baz"}},
+				},
+			},
+		}}
+	)
+	testCases := []struct {
+		name string
+		opts []parser.PrintOption
+		want string
+	}{
+		{
+			name: "absolute",
+			opts: nil,
+			want: `.
+├── foo <- /home/me/.config/foo
+├── bar <- /home/me
+└── baz <- /etc/baz
+`,
+		},
+		{
+			name: "home",
+			opts: []parser.PrintOption{parser.HomePaths(home)},
+			want: `.
+├── foo <- ~/.config/foo
+├── bar <- ~
+└── baz <- /etc/baz
+`,
+		},
+		{
+			name: "relative",
+			opts: []parser.PrintOption{parser.RelativePaths},
+			want: `.
+├── foo <- ../.config/foo
+├── bar <- ..
+└── baz <- ../../../etc/baz
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bd strings.Builder
+			if err := tr.Print(&bd, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := filepath.FromSlash(tc.want), bd.String(); got != want {
+				t.Errorf("\nwant\n%s\ngot\n%s", want, got)
+				t.Logf("\ndiff (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func graphTree() *parser.Tree {
+	var (
+		home     = filepath.FromSlash("/home/me")
+		dotfiles = filepath.Join(home, "dotfiles")
+		config   = filepath.Join(home, ".config")
+	)
+	return &parser.Tree{&parser.Node{
+		Children: []*parser.Node{
+			{
+				Target: parser.File{dotfiles, []string{"foo"}},
+				Link:   parser.File{config, []string{"foo"}},
+				Status: parser.StatusDone,
+			},
+			{
+				Target: parser.File{dotfiles, []string{"bar"}},
+				Link:   parser.File{config, []string{"bar"}},
+				Status: parser.StatusSkip,
+				Children: []*parser.Node{
+					{
+						Target: parser.File{dotfiles, []string{"bar", "baz"}},
+						Link:   parser.File{config, []string{"bar", "baz"}},
+						Status: parser.StatusReady,
+					},
+				},
+			},
+			{
+				Target: parser.File{dotfiles, []string{"qux"}},
+				Link:   parser.File{home, []string{".qux"}},
+				Status: parser.StatusConflict,
+			},
+		},
+	}}
+}
+
+func testTreeWriteDot(t *testing.T) {
+	testCases := []struct {
+		name string
+		tr   *parser.Tree
+		opts []parser.PrintOption
+		want string
+	}{
+		{
+			name: "resolved",
+			tr:   graphTree(),
+			opts: []parser.PrintOption{parser.HomePaths(filepath.FromSlash("/home/me"))},
+			want: `digraph pilgo {
+	rankdir=LR;
+	subgraph cluster_0 {
+		label="~/dotfiles";
+		t0 [label="foo", color="#228b22"];
+		t1 [label="bar/baz", color="#daa520"];
+		t2 [label="qux", color="#dc143c"];
+	}
+	subgraph cluster_1 {
+		label="~/.config";
+		l0 [label="foo"];
+		l1 [label="bar/baz"];
+	}
+	subgraph cluster_2 {
+		label="~";
+		l2 [label=".qux"];
+	}
+	t0 -> l0 [color="#228b22"];
+	t1 -> l1 [color="#daa520"];
+	t2 -> l2 [color="#dc143c"];
+}
+`,
+		},
+		{
+			name: "unresolved",
+			tr: &parser.Tree{&parser.Node{
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Link:   parser.File{"test", []string{"foo"}},
+					},
+				},
+			}},
+			opts: nil,
+			want: `digraph pilgo {
+	rankdir=LR;
+	subgraph cluster_0 {
+		label="";
+		t0 [label="foo"];
+	}
+	subgraph cluster_1 {
+		label="test";
+		l0 [label="foo"];
+	}
+	t0 -> l0;
+}
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bd strings.Builder
+			if err := tc.tr.WriteDot(&bd, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			// Backslashes are escaped in labels.
+			want := strings.ReplaceAll(filepath.FromSlash(tc.want), `\`, `\\`)
+			if got := bd.String(); got != want {
+				t.Errorf("\nwant\n%s\ngot\n%s", want, got)
+				t.Logf("\ndiff (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func testTreeWriteMermaid(t *testing.T) {
+	testCases := []struct {
+		name string
+		tr   *parser.Tree
+		opts []parser.PrintOption
+		want string
+	}{
+		{
+			name: "resolved",
+			tr:   graphTree(),
+			opts: []parser.PrintOption{parser.RelativePaths},
+			want: `flowchart LR
+	subgraph c0 ["."]
+		t0["foo"]
+		t1["bar/baz"]
+		t2["qux"]
+	end
+	subgraph c1 ["../.config"]
+		l0["foo"]
+		l1["bar/baz"]
+	end
+	subgraph c2 [".."]
+		l2[".qux"]
+	end
+	t0 --> l0
+	t1 --> l1
+	t2 --> l2
+	classDef done stroke:#228b22
+	class t0 done
+	classDef ready stroke:#daa520
+	class t1 ready
+	classDef conflict stroke:#dc143c
+	class t2 conflict
+	linkStyle 0 stroke:#228b22
+	linkStyle 1 stroke:#daa520
+	linkStyle 2 stroke:#dc143c
+`,
+		},
+		{
+			name: "unresolved",
+			tr: &parser.Tree{&parser.Node{
+				Children: []*parser.Node{
+					{
+						Target: parser.File{"", []string{"foo"}},
+						Link:   parser.File{"test", []string{"foo"}},
+					},
+				},
+			}},
+			opts: nil,
+			want: `flowchart LR
+	subgraph c0 [""]
+		t0["foo"]
+	end
+	subgraph c1 ["test"]
+		l0["foo"]
+	end
+	t0 --> l0
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bd strings.Builder
+			if err := tc.tr.WriteMermaid(&bd, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := filepath.FromSlash(tc.want), bd.String(); got != want {
+				t.Errorf("\nwant\n%s\ngot\n%s", want, got)
+				t.Logf("\ndiff (-want +got):\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
 }
 
 func testTreeString(t *testing.T) {